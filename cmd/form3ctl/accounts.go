@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"form3-interview-accountapi/form3"
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var errUsage = errors.New("invalid usage")
+
+type accountsCommand struct {
+	service form3.AccountsAPI
+	printer printer
+	stdout  io.Writer
+}
+
+func newAccountsCommand(cfg config, stdout io.Writer) (*accountsCommand, error) {
+	p, err := newPrinter(cfg.output)
+	if err != nil {
+		return nil, err
+	}
+
+	params, err := cfg.client.Params()
+	if err != nil {
+		return nil, err
+	}
+	client, err := form3.NewRestClient(nil, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &accountsCommand{
+		service: form3.NewAccountsService(client),
+		printer: p,
+		stdout:  stdout,
+	}, nil
+}
+
+func (c *accountsCommand) run(ctx context.Context, name string, args []string) error {
+	switch name {
+	case "create":
+		return c.create(ctx, args)
+	case "get":
+		return c.get(ctx, args)
+	case "delete":
+		return c.delete(ctx, args)
+	case "list":
+		return c.list(ctx, args)
+	}
+
+	return errUsage
+}
+
+func (c *accountsCommand) create(ctx context.Context, args []string) error {
+	flags := newFlagSet("accounts create")
+	file := flags.String("file", "", "JSON or YAML file holding the account, flags override its values")
+	id := flags.String("id", "", "account id, a random UUID by default")
+	organisationID := flags.String("organisation-id", "", "organisation id")
+	country := flags.String("country", "", "ISO 3166-1 country code")
+	bankID := flags.String("bank-id", "", "local country bank identifier")
+	bankIDCode := flags.String("bank-id-code", "", "identifies the type of bank ID being used")
+	bic := flags.String("bic", "", "SWIFT BIC")
+	baseCurrency := flags.String("base-currency", "", "ISO 4217 currency code")
+	accountNumber := flags.String("account-number", "", "account number")
+	iban := flags.String("iban", "", "IBAN of the account")
+	classification := flags.String("classification", "", "Personal or Business")
+	name := flags.String("name", "", "comma separated name lines of the account holder")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	account := &form3.Account{}
+	if *file != "" {
+		var err error
+		if account, err = readAccountFile(*file); err != nil {
+			return err
+		}
+	}
+	if account.Attributes == nil {
+		account.Attributes = &form3.AccountAttributes{}
+	}
+	if account.Type == "" {
+		account.Type = form3.AcctTypeAccounts
+	}
+
+	setIfNotEmpty(&account.ID, *id)
+	setIfNotEmpty(&account.OrganisationID, *organisationID)
+	setIfNotEmpty((*string)(&account.Attributes.Country), *country)
+	setIfNotEmpty(&account.Attributes.BankID, *bankID)
+	setIfNotEmpty((*string)(&account.Attributes.BankIDCode), *bankIDCode)
+	setIfNotEmpty(&account.Attributes.Bic, *bic)
+	setIfNotEmpty((*string)(&account.Attributes.BaseCurrency), *baseCurrency)
+	setIfNotEmpty(&account.Attributes.AccountNumber, *accountNumber)
+	setIfNotEmpty(&account.Attributes.Iban, *iban)
+	setIfNotEmpty((*string)(&account.Attributes.AccountClassification), *classification)
+	if *name != "" {
+		account.Attributes.Name = strings.Split(*name, ",")
+	}
+	if account.ID == "" {
+		account.ID = uuid.New().String()
+	}
+
+	newAccount, _, err := c.service.Create(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	return c.printer.account(c.stdout, *newAccount)
+}
+
+func (c *accountsCommand) get(ctx context.Context, args []string) error {
+	flags := newFlagSet("accounts get <id>")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errUsage
+	}
+
+	account, _, err := c.service.Get(ctx, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	return c.printer.account(c.stdout, *account)
+}
+
+func (c *accountsCommand) delete(ctx context.Context, args []string) error {
+	flags := newFlagSet("accounts delete <id>")
+	version := flags.Int("version", -1, "version of the account, the current one is looked up if not given")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errUsage
+	}
+	id := flags.Arg(0)
+
+	var err error
+	if *version < 0 {
+		_, err = c.service.DeleteLatest(ctx, id)
+	} else {
+		_, err = c.service.Delete(ctx, id, *version)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "account %s deleted\n", id)
+	return nil
+}
+
+func (c *accountsCommand) list(ctx context.Context, args []string) error {
+	flags := newFlagSet("accounts list")
+	pageNumber := flags.Int("page-number", 0, "page to retrieve")
+	pageSize := flags.Int("page-size", 0, "number of accounts per page")
+	filters := filterFlag{}
+	flags.Var(filters, "filter", "key=value filter, e.g. country=GB, may be repeated")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	accounts, _, err := c.service.List(ctx, &form3.ListOptions{
+		PageNumber: *pageNumber,
+		PageSize:   *pageSize,
+		Filter:     filters,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.printer.accounts(c.stdout, accounts)
+}
+
+// readAccountFile decodes an account from a JSON or YAML file. Both formats use the API field names.
+func readAccountFile(path string) (*form3.Account, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		var decoded any
+		if err = yaml.Unmarshal(data, &decoded); err != nil {
+			return nil, fmt.Errorf("reading %s: %w", path, err)
+		}
+		if data, err = json.Marshal(decoded); err != nil {
+			return nil, fmt.Errorf("reading %s: %w", path, err)
+		}
+	}
+
+	account := &form3.Account{}
+	if err = json.Unmarshal(data, account); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	return account, nil
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: form3ctl %s\n", name)
+		flags.PrintDefaults()
+	}
+
+	return flags
+}
+
+func setIfNotEmpty(target *string, value string) {
+	if value != "" {
+		*target = value
+	}
+}
+
+// filterFlag collects repeated key=value flags into a map
+type filterFlag map[string]string
+
+func (f filterFlag) String() string {
+	return ""
+}
+
+func (f filterFlag) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("filter %q must be in key=value format", value)
+	}
+	f[key] = val
+
+	return nil
+}
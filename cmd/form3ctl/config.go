@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"form3-interview-accountapi/form3"
+	"os"
+	"path/filepath"
+)
+
+const defaultApiUrl = "http://localhost:8080/v1"
+
+// settings are the form3ctl settings of the client configuration file, read next to the client ones:
+//
+//	default_profile: local
+//	profiles:
+//	  local:
+//	    base_url: http://localhost:8080/v1
+//	  staging:
+//	    base_url: https://staging.example.com/v1
+//	    output: json
+type settings struct {
+	Output string `yaml:"output"`
+}
+
+type config struct {
+	client *form3.ClientConfig
+	output string
+}
+
+// loadConfig resolves the configuration from the selected profile and the environment, like
+// form3.NewRestClientFromEnv: the file is FORM3_CONFIG, ~/.config/form3ctl/config.yaml by default,
+// the profile is FORM3_PROFILE unless given, and API_URL and the other variables override the profile.
+func loadConfig(profile string, getenv func(string) string) (config, error) {
+	cfg := config{client: &form3.ClientConfig{}, output: "table"}
+
+	if profile == "" {
+		profile = getenv(form3.EnvProfile)
+	}
+
+	path := configPath(getenv)
+	client, extra, err := form3.LoadConfigWith[settings](path, profile)
+	switch {
+	case err == nil:
+		cfg.client = client
+		if extra.Output != "" {
+			cfg.output = extra.Output
+		}
+	case os.IsNotExist(err) && profile != "":
+		return cfg, fmt.Errorf("profile %q not found, there is no config file %s", profile, path)
+	case !os.IsNotExist(err):
+		return cfg, err
+	}
+
+	if cfg.client.BaseURL == "" {
+		cfg.client.BaseURL = defaultApiUrl
+	}
+	if err = cfg.client.ApplyEnv(getenv); err != nil {
+		return cfg, err
+	}
+
+	return cfg, nil
+}
+
+func configPath(getenv func(string) string) string {
+	if path := getenv(form3.EnvConfigFile); path != "" {
+		return path
+	}
+	if home := getenv("HOME"); home != "" {
+		return filepath.Join(home, ".config", "form3ctl", "config.yaml")
+	}
+
+	return ""
+}
@@ -0,0 +1,81 @@
+// Command form3ctl inspects and manages accounts of the Form3 account API.
+//
+// Usage:
+//
+//	form3ctl [-profile name] [-api-url url] [-output table|json|yaml] accounts <create|get|delete|list> [flags]
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+const usage = `Usage: form3ctl [-profile name] [-api-url url] [-output table|json|yaml] <command>
+
+Commands:
+  accounts create  creates an account from a JSON/YAML file or flags
+  accounts get     retrieves an account by its id
+  accounts delete  deletes an account, looking up its current version if not given
+  accounts list    lists accounts matching the given filters
+`
+
+func main() {
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the command line given in args and returns the process exit code
+func run(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("form3ctl", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() { fmt.Fprint(stderr, usage) }
+	profile := flags.String("profile", "", "configuration profile to use, FORM3_PROFILE by default")
+	apiUrl := flags.String("api-url", "", "base URL of the API, overrides API_URL and the profile")
+	output := flags.String("output", "", "output format: table, json or yaml")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+
+	cfg, err := loadConfig(*profile, os.Getenv)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	}
+	if *apiUrl != "" {
+		cfg.client.BaseURL = *apiUrl
+	}
+	if *output != "" {
+		cfg.output = *output
+	}
+
+	if flags.NArg() < 2 || flags.Arg(0) != "accounts" {
+		flags.Usage()
+		return 2
+	}
+
+	cmd, err := newAccountsCommand(cfg, stdout)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	}
+
+	if err = cmd.run(ctx, flags.Arg(1), flags.Args()[2:]); err != nil {
+		if errors.Is(err, errUsage) {
+			flags.Usage()
+			return 2
+		}
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
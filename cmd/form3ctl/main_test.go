@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testAccount = `{"id":"a1b2c3","organisation_id":"b","type":"accounts","version":2,"attributes":{"country":"BE","bank_id":"ZXE","name":["cristian","pelegrin"],"status":"pending"}}`
+
+func newTestAPI(t *testing.T, handler http.HandlerFunc) string {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	t.Setenv("FORM3_CONFIG", filepath.Join(t.TempDir(), "missing.yaml"))
+	t.Setenv("API_URL", server.URL+"/v1")
+
+	return server.URL + "/v1"
+}
+
+func runCommand(args ...string) (int, string, string) {
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	code := run(context.Background(), args, stdout, stderr)
+
+	return code, stdout.String(), stderr.String()
+}
+
+func TestAccountsGet_table(t *testing.T) {
+	newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/organisation/accounts/a1b2c3", r.URL.Path)
+		_, _ = w.Write([]byte(`{"data":` + testAccount + `}`))
+	})
+
+	code, stdout, _ := runCommand("accounts", "get", "a1b2c3")
+
+	assert.Equal(t, 0, code, "Exit code incorrect")
+	assert.Equal(t, "ID      ORGANISATION ID  COUNTRY  BANK ID  IBAN  NAME               STATUS   VERSION\n"+
+		"a1b2c3  b                BE       ZXE            cristian pelegrin  pending  2\n", stdout, "Output incorrect")
+}
+
+func TestAccountsGet_yaml(t *testing.T) {
+	newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":` + testAccount + `}`))
+	})
+
+	code, stdout, _ := runCommand("-output", "yaml", "accounts", "get", "a1b2c3")
+
+	assert.Equal(t, 0, code, "Exit code incorrect")
+	assert.Contains(t, stdout, "organisation_id: b\n", "Output incorrect")
+	assert.Contains(t, stdout, "  bank_id: ZXE\n", "Output incorrect")
+}
+
+func TestAccountsGet_errorResponse(t *testing.T) {
+	newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error_message":"record a1b2c3 does not exist"}`))
+	})
+
+	code, _, stderr := runCommand("accounts", "get", "a1b2c3")
+
+	assert.Equal(t, 1, code, "Exit code incorrect")
+	assert.Equal(t, "error: record a1b2c3 does not exist\n", stderr, "Error output incorrect")
+}
+
+func TestAccountsDelete_looksUpVersion(t *testing.T) {
+	var deleteQuery string
+	newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			_, _ = w.Write([]byte(`{"data":` + testAccount + `}`))
+		case http.MethodDelete:
+			deleteQuery = r.URL.RawQuery
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+
+	code, stdout, _ := runCommand("accounts", "delete", "a1b2c3")
+
+	assert.Equal(t, 0, code, "Exit code incorrect")
+	assert.Equal(t, "version=2", deleteQuery, "Delete version incorrect")
+	assert.Equal(t, "account a1b2c3 deleted\n", stdout, "Output incorrect")
+}
+
+func TestAccountsList_json(t *testing.T) {
+	newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "BE", r.URL.Query().Get("filter[country]"))
+		assert.Equal(t, "10", r.URL.Query().Get("page[size]"))
+		_, _ = w.Write([]byte(`{"data":[` + testAccount + `]}`))
+	})
+
+	code, stdout, _ := runCommand("-output", "json", "accounts", "list", "-page-size", "10", "-filter", "country=BE")
+
+	assert.Equal(t, 0, code, "Exit code incorrect")
+	expected := `[
+  {
+    "id": "a1b2c3",
+    "organisation_id": "b",
+    "type": "accounts",
+    "attributes": {
+      "bank_id": "ZXE",
+      "country": "BE",
+      "name": [
+        "cristian",
+        "pelegrin"
+      ],
+      "status": "pending"
+    },
+    "version": 2
+  }
+]
+`
+	assert.Equal(t, expected, stdout, "Output incorrect")
+}
+
+func TestAccountsCreate_fromYamlFile(t *testing.T) {
+	var requestBody string
+	newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requestBody = string(body)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"data":` + testAccount + `}`))
+	})
+	file := filepath.Join(t.TempDir(), "account.yaml")
+	content := "id: a1b2c3\norganisation_id: b\nattributes:\n  country: GB\n  name: [cristian]\n"
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatalf("Error writing account file: %v", err)
+	}
+
+	code, _, stderr := runCommand("accounts", "create", "-file", file, "-country", "BE")
+
+	assert.Equal(t, 0, code, "Exit code incorrect: %s", stderr)
+	assert.Equal(t, `{"data":{"id":"a1b2c3","organisation_id":"b","type":"accounts","attributes":{"country":"BE","name":["cristian"]},"version":0}}`, requestBody, "Request body incorrect")
+}
+
+func TestRun_usage(t *testing.T) {
+	code, _, stderr := runCommand("accounts", "rename")
+
+	assert.Equal(t, 2, code, "Exit code incorrect")
+	assert.Contains(t, stderr, "Usage: form3ctl", "Usage should be printed")
+}
+
+func TestRun_help(t *testing.T) {
+	newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {})
+
+	for _, args := range [][]string{{"-h"}, {"--help"}} {
+		code, _, stderr := runCommand(args...)
+
+		assert.Equal(t, 0, code, "Exit code of %v incorrect", args)
+		assert.Contains(t, stderr, "Usage: form3ctl", "Usage of %v should be printed", args)
+	}
+}
+
+func TestLoadConfig_profile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	content := "default_profile: local\nprofiles:\n  local:\n    base_url: http://localhost:8080/v1\n  staging:\n    base_url: https://staging.example.com/v1\n    retry: {max_attempts: 3}\n    output: json\n"
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatalf("Error writing config file: %v", err)
+	}
+	env := map[string]string{"FORM3_CONFIG": file}
+	getenv := func(key string) string { return env[key] }
+
+	cfg, err := loadConfig("", getenv)
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, "http://localhost:8080/v1", cfg.client.BaseURL, "Default profile BaseURL incorrect")
+	assert.Equal(t, "table", cfg.output, "Default profile output incorrect")
+
+	cfg, err = loadConfig("staging", getenv)
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, "https://staging.example.com/v1", cfg.client.BaseURL, "Staging BaseURL incorrect")
+	assert.Equal(t, 3, cfg.client.Retry.MaxAttempts, "Staging retry attempts incorrect")
+	assert.Equal(t, "json", cfg.output, "Staging output incorrect")
+
+	env["API_URL"] = "http://account_api:8080/v1"
+	cfg, err = loadConfig("staging", getenv)
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, "http://account_api:8080/v1", cfg.client.BaseURL, "API_URL should override the profile")
+
+	_, err = loadConfig("production", getenv)
+	assert.Equal(t, "invalid config profiles.production: profile not found", err.Error(), "Error message expected")
+}
+
+func TestLoadConfig_noFile(t *testing.T) {
+	env := map[string]string{"FORM3_CONFIG": filepath.Join(t.TempDir(), "missing.yaml")}
+	getenv := func(key string) string { return env[key] }
+
+	cfg, err := loadConfig("", getenv)
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, defaultApiUrl, cfg.client.BaseURL, "Default BaseURL incorrect")
+
+	_, err = loadConfig("staging", getenv)
+	assert.NotNil(t, err, "A profile without config file should be an error")
+}
+
+func TestLoadConfig_unknownKey(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(file, []byte("api_url: http://localhost:8080/v1\n"), 0o644); err != nil {
+		t.Fatalf("Error writing config file: %v", err)
+	}
+
+	_, err := loadConfig("", func(key string) string { return map[string]string{"FORM3_CONFIG": file}[key] })
+
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Contains(t, err.Error(), "field api_url not found", "Error message expected")
+}
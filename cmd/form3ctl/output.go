@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"form3-interview-accountapi/form3"
+	"gopkg.in/yaml.v3"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// printer writes a single account, e.g. a created one, or a list of accounts, printed as a list
+// even when it has one account or none
+type printer interface {
+	account(w io.Writer, account form3.Account) error
+	accounts(w io.Writer, accounts []form3.Account) error
+}
+
+func newPrinter(format string) (printer, error) {
+	switch format {
+	case "table", "":
+		return tablePrinter{}, nil
+	case "json":
+		return jsonPrinter{}, nil
+	case "yaml":
+		return yamlPrinter{}, nil
+	}
+
+	return nil, fmt.Errorf("unknown output format %q", format)
+}
+
+type tablePrinter struct{}
+
+func (p tablePrinter) account(w io.Writer, account form3.Account) error {
+	return p.accounts(w, []form3.Account{account})
+}
+
+func (tablePrinter) accounts(w io.Writer, accounts []form3.Account) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tORGANISATION ID\tCOUNTRY\tBANK ID\tIBAN\tNAME\tSTATUS\tVERSION")
+	for _, account := range accounts {
+		attributes := account.Attributes
+		if attributes == nil {
+			attributes = &form3.AccountAttributes{}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%d\n",
+			account.ID,
+			account.OrganisationID,
+			attributes.Country,
+			attributes.BankID,
+			attributes.Iban,
+			strings.Join(attributes.Name, " "),
+			attributes.Status,
+			account.Version,
+		)
+	}
+
+	return tw.Flush()
+}
+
+type jsonPrinter struct{}
+
+// account prints the account as an object
+func (p jsonPrinter) account(w io.Writer, account form3.Account) error {
+	return p.encode(w, account)
+}
+
+// accounts prints the accounts as an array
+func (p jsonPrinter) accounts(w io.Writer, accounts []form3.Account) error {
+	if accounts == nil {
+		accounts = []form3.Account{}
+	}
+
+	return p.encode(w, accounts)
+}
+
+func (jsonPrinter) encode(w io.Writer, value any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(value)
+}
+
+type yamlPrinter struct{}
+
+// account prints the account as a mapping
+func (p yamlPrinter) account(w io.Writer, account form3.Account) error {
+	return p.encode(w, account)
+}
+
+// accounts prints the accounts as a sequence
+func (p yamlPrinter) accounts(w io.Writer, accounts []form3.Account) error {
+	if accounts == nil {
+		accounts = []form3.Account{}
+	}
+
+	return p.encode(w, accounts)
+}
+
+// encode prints the value as YAML using the API field names
+func (yamlPrinter) encode(w io.Writer, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	var decoded any
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err = encoder.Encode(decoded); err != nil {
+		return err
+	}
+
+	return encoder.Close()
+}
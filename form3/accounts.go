@@ -2,10 +2,70 @@ package form3
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 )
 
-const accountsBasePath = "organisation/accounts"
+const (
+	accountsBasePath = "organisation/accounts"
+
+	defaultDeleteLatestAttempts = 3
+)
+
+// ErrStatusUpdate is returned by AccountsService.Update for a patch changing the status, which is only
+// changed by Transition so the allowed transitions are checked
+var ErrStatusUpdate = errors.New("the status cannot be updated, use Transition")
+
+// VersionConflictError is returned by AccountsService.DeleteLatest when the version of the account
+// kept changing between the fetch and the delete on every attempt. Err is the error of the last delete.
+type VersionConflictError struct {
+	AccountID string
+	Attempts  int
+	Err       error
+}
+
+func (e *VersionConflictError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("record %s version kept changing after %d attempts", e.AccountID, e.Attempts)
+	}
+
+	return fmt.Sprintf("record %s version kept changing after %d attempts: %v", e.AccountID, e.Attempts, e.Err)
+}
+
+func (e *VersionConflictError) Unwrap() error {
+	return e.Err
+}
+
+type deleteLatestConfig struct {
+	maxAttempts       int
+	notFoundAsSuccess bool
+	callOpts          []CallOption
+}
+
+// DeleteLatestOption configures the behaviour of AccountsService.DeleteLatest
+type DeleteLatestOption func(*deleteLatestConfig)
+
+// WithMaxAttempts sets how many times the fetch and delete is attempted when the version conflicts
+func WithMaxAttempts(attempts int) DeleteLatestOption {
+	return func(c *deleteLatestConfig) {
+		c.maxAttempts = attempts
+	}
+}
+
+// WithCallOptions sets the CallOption of the Get and Delete calls made by DeleteLatest
+func WithCallOptions(opts ...CallOption) DeleteLatestOption {
+	return func(c *deleteLatestConfig) {
+		c.callOpts = append(c.callOpts, opts...)
+	}
+}
+
+// WithNotFoundAsSuccess makes a missing account a successful delete, useful for idempotent cleanups
+func WithNotFoundAsSuccess() DeleteLatestOption {
+	return func(c *deleteLatestConfig) {
+		c.notFoundAsSuccess = true
+	}
+}
 
 // NewAccountsService returns a AccountsService instance.
 func NewAccountsService(client *RestClient) *AccountsService {
@@ -17,14 +77,14 @@ func NewAccountsService(client *RestClient) *AccountsService {
 }
 
 // Create creates a new account and returns it.
-func (s *AccountsService) Create(ctx context.Context, data *Account) (*Account, *RestClientResponse, error) {
-	req, err := s.client.PostRequest(accountsBasePath, data)
+func (s *AccountsService) Create(ctx context.Context, data *Account, opts ...CallOption) (*Account, *RestClientResponse, error) {
+	req, err := s.client.PostRequest(accountsBasePath, data, opts...)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	account := new(Account)
-	resp, err := s.client.Do(ctx, req.Request, account)
+	resp, err := s.client.Do(ctx, req.Request, account, opts...)
 	if err != nil {
 		return nil, resp, err
 	}
@@ -33,16 +93,65 @@ func (s *AccountsService) Create(ctx context.Context, data *Account) (*Account,
 }
 
 // Get retrieves an account by its id
-func (s *AccountsService) Get(ctx context.Context, id string) (*Account, *RestClientResponse, error) {
+func (s *AccountsService) Get(ctx context.Context, id string, opts ...CallOption) (*Account, *RestClientResponse, error) {
+	path := fmt.Sprintf("%s/%s", accountsBasePath, id)
+
+	req, err := s.client.GetRequest(path, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	account := new(Account)
+	resp, err := s.client.Do(ctx, req.Request, account, opts...)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return account, resp, nil
+}
+
+// List retrieves a page of accounts matching the paging and filter options
+func (s *AccountsService) List(ctx context.Context, listOpts *ListOptions, opts ...CallOption) ([]Account, *RestClientResponse, error) {
+	req, err := s.client.GetRequest(listPath(accountsBasePath, listOpts), opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var accounts []Account
+	resp, err := s.client.Do(ctx, req.Request, &accounts, opts...)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return accounts, resp, nil
+}
+
+// Update changes the present attributes of an account by its id and version, and returns the updated account.
+// The status is changed with Transition instead, a patch setting it returns ErrStatusUpdate.
+func (s *AccountsService) Update(ctx context.Context, id string, version int, attributes *AccountAttributesPatch, opts ...CallOption) (*Account, *RestClientResponse, error) {
+	if attributes != nil && attributes.Status.IsPresent() {
+		return nil, nil, ErrStatusUpdate
+	}
+
+	return s.update(ctx, id, version, attributes, opts...)
+}
+
+func (s *AccountsService) update(ctx context.Context, id string, version int, attributes *AccountAttributesPatch, opts ...CallOption) (*Account, *RestClientResponse, error) {
 	path := fmt.Sprintf("%s/%s", accountsBasePath, id)
+	data := &AccountPatch{
+		ID:         id,
+		Type:       AcctTypeAccounts,
+		Version:    version,
+		Attributes: attributes,
+	}
 
-	req, err := s.client.GetRequest(path)
+	req, err := s.client.PatchRequest(path, data, opts...)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	account := new(Account)
-	resp, err := s.client.Do(ctx, req.Request, account)
+	resp, err := s.client.Do(ctx, req.Request, account, opts...)
 	if err != nil {
 		return nil, resp, err
 	}
@@ -51,18 +160,65 @@ func (s *AccountsService) Get(ctx context.Context, id string) (*Account, *RestCl
 }
 
 // Delete deletes an account by its id and version
-func (s *AccountsService) Delete(ctx context.Context, id string, version int) (*RestClientResponse, error) {
+func (s *AccountsService) Delete(ctx context.Context, id string, version int, opts ...CallOption) (*RestClientResponse, error) {
 	url := fmt.Sprintf("%s/%s?version=%d", accountsBasePath, id, version)
 
-	req, err := s.client.DeleteRequest(url)
+	req, err := s.client.DeleteRequest(url, opts...)
 	if err != nil {
-		return nil, nil
+		return nil, err
 	}
 
-	resp, err := s.client.Do(ctx, req.Request, nil)
+	resp, err := s.client.Do(ctx, req.Request, nil, opts...)
 	if err != nil {
 		return resp, err
 	}
 
 	return resp, nil
 }
+
+// DeleteLatest deletes an account by its id fetching its current version first.
+// The fetch and delete is retried when the version changes in between, up to 3 attempts by default,
+// then a *VersionConflictError is returned.
+func (s *AccountsService) DeleteLatest(ctx context.Context, id string, opts ...DeleteLatestOption) (*RestClientResponse, error) {
+	config := &deleteLatestConfig{maxAttempts: defaultDeleteLatestAttempts}
+	for _, opt := range opts {
+		opt(config)
+	}
+	if config.maxAttempts < 1 {
+		config.maxAttempts = 1
+	}
+
+	var resp *RestClientResponse
+	var lastErr error
+	for attempt := 0; attempt < config.maxAttempts; attempt++ {
+		var account *Account
+		var err error
+		account, resp, err = s.Get(ctx, id, config.callOpts...)
+		if err != nil {
+			if config.notFoundAsSuccess && isStatus(resp, http.StatusNotFound) {
+				return resp, nil
+			}
+			return resp, err
+		}
+
+		resp, err = s.Delete(ctx, id, account.Version, config.callOpts...)
+		if isStatus(resp, http.StatusConflict) {
+			lastErr = err
+			continue
+		}
+		if isStatus(resp, http.StatusNotFound) {
+			if config.notFoundAsSuccess {
+				return resp, nil
+			}
+			return resp, fmt.Errorf("record %s does not exist", id)
+		}
+
+		return resp, err
+	}
+
+	return resp, &VersionConflictError{AccountID: id, Attempts: config.maxAttempts, Err: lastErr}
+}
+
+func isStatus(resp *RestClientResponse, statusCode int) bool {
+	return resp != nil && resp.StatusCode == statusCode
+}
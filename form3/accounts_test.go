@@ -65,6 +65,68 @@ func TestAccountsService_Get(t *testing.T) {
 	assert.Equal(t, 1, account.Version, "newAccount.Version incorrect")
 }
 
+func TestAccountsService_List(t *testing.T) {
+	mockedHttpClient := mockedHttpClientHandler(func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, "GET", req.Method)
+		assert.Equal(t, fmt.Sprintf("%s/%s", baseFakeUrl, "organisation/accounts?filter%5Bcountry%5D=BE&page%5Bsize%5D=2"), req.URL.String())
+
+		body := `{"data":[{"id":"a1","organisation_id":"b","type":"accounts","version":0},{"id":"a2","organisation_id":"b","type":"accounts","version":3}]}`
+		return mockedResponse(http.StatusOK, body, nil), nil
+	})
+	client, err := NewRestClient(mockedHttpClient, NewRestClientParams{BaseUrl: baseFakeUrl})
+	service := NewAccountsService(client)
+
+	accounts, resp, err := service.List(context.Background(), &ListOptions{PageSize: 2, Filter: map[string]string{"country": "BE"}})
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.NotNil(t, resp, "Response should be not nil")
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "Response code incorrect")
+	assert.Len(t, accounts, 2, "Accounts length incorrect")
+	assert.Equal(t, "a2", accounts[1].ID, "accounts[1].ID incorrect")
+	assert.Equal(t, 3, accounts[1].Version, "accounts[1].Version incorrect")
+}
+
+func TestAccountsService_Update(t *testing.T) {
+	mockedHttpClient := mockedHttpClientHandler(func(req *http.Request) (*http.Response, error) {
+		testRequest(t, req, testRequestExpected{
+			method: "PATCH",
+			path:   "organisation/accounts/a1b2c3",
+			body:   `{"data":{"id":"a1b2c3","type":"accounts","version":1,"attributes":{"account_matching_opt_out":false,"secondary_identification":null}}}`,
+		})
+
+		body := `{"data":{"id":"a1b2c3","organisation_id":"b","type":"accounts","version":2}}`
+		return mockedResponse(http.StatusOK, body, nil), nil
+	})
+	client, err := NewRestClient(mockedHttpClient, NewRestClientParams{BaseUrl: baseFakeUrl})
+	service := NewAccountsService(client)
+
+	patch := &AccountAttributesPatch{
+		AccountMatchingOptOut:   Some(false),
+		SecondaryIdentification: Null[string](),
+	}
+	account, resp, err := service.Update(context.Background(), "a1b2c3", 1, patch)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "Response code incorrect")
+	assert.Equal(t, 2, account.Version, "account.Version incorrect")
+}
+
+func TestAccountsService_Update_status(t *testing.T) {
+	mockedHttpClient := mockedHttpClientHandler(func(req *http.Request) (*http.Response, error) {
+		t.Errorf("Unexpected request %s %s", req.Method, req.URL)
+		return mockedResponse(http.StatusOK, "", nil), nil
+	})
+	client, err := NewRestClient(mockedHttpClient, NewRestClientParams{BaseUrl: baseFakeUrl})
+	service := NewAccountsService(client)
+
+	patch := &AccountAttributesPatch{Status: Some(AcctStatusClosed)}
+	account, resp, err := service.Update(context.Background(), "a1b2c3", 1, patch)
+
+	assert.Equal(t, ErrStatusUpdate, err, "Error incorrect")
+	assert.Nil(t, resp, "Response should be nil")
+	assert.Nil(t, account, "Account should be nil")
+}
+
 func TestAccountsService_Delete(t *testing.T) {
 	mockedHttpClient := mockedHttpClientHandler(func(req *http.Request) (*http.Response, error) {
 		assert.Equal(t, "DELETE", req.Method)
@@ -82,3 +144,107 @@ func TestAccountsService_Delete(t *testing.T) {
 	assert.Equal(t, http.StatusNoContent, resp.StatusCode, "Response code incorrect")
 	assert.Equal(t, resp.Body, http.NoBody)
 }
+
+func TestAccountsService_DeleteLatest(t *testing.T) {
+	mockedHttpClient := mockedHttpClientHandler(func(req *http.Request) (*http.Response, error) {
+		switch req.Method {
+		case "GET":
+			assert.Equal(t, fmt.Sprintf("%s/%s", baseFakeUrl, "organisation/accounts/a1b2c3"), req.URL.String())
+			return mockedResponse(http.StatusOK, `{"data":{"id":"a1b2c3","type":"accounts","version":4}}`, nil), nil
+		default:
+			assert.Equal(t, fmt.Sprintf("%s/%s", baseFakeUrl, "organisation/accounts/a1b2c3?version=4"), req.URL.String())
+			return mockedResponse(http.StatusNoContent, "", nil), nil
+		}
+	})
+	client, err := NewRestClient(mockedHttpClient, NewRestClientParams{BaseUrl: baseFakeUrl})
+	service := NewAccountsService(client)
+
+	resp, err := service.DeleteLatest(context.Background(), "a1b2c3")
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode, "Response code incorrect")
+}
+
+func TestAccountsService_DeleteLatest_retriesVersionConflict(t *testing.T) {
+	version := 0
+	deletes := 0
+	mockedHttpClient := mockedHttpClientHandler(func(req *http.Request) (*http.Response, error) {
+		if req.Method == "GET" {
+			version++
+			return mockedResponse(http.StatusOK, fmt.Sprintf(`{"data":{"id":"a1b2c3","type":"accounts","version":%d}}`, version), nil), nil
+		}
+
+		deletes++
+		if req.URL.Query().Get("version") != "2" {
+			return mockedResponse(http.StatusConflict, `{"error_message":"invalid version"}`, nil), nil
+		}
+		return mockedResponse(http.StatusNoContent, "", nil), nil
+	})
+	client, err := NewRestClient(mockedHttpClient, NewRestClientParams{BaseUrl: baseFakeUrl})
+	service := NewAccountsService(client)
+
+	resp, err := service.DeleteLatest(context.Background(), "a1b2c3")
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode, "Response code incorrect")
+	assert.Equal(t, 2, deletes, "Delete attempts incorrect")
+}
+
+func TestAccountsService_DeleteLatest_maxAttemptsReached(t *testing.T) {
+	deletes := 0
+	mockedHttpClient := mockedHttpClientHandler(func(req *http.Request) (*http.Response, error) {
+		if req.Method == "GET" {
+			return mockedResponse(http.StatusOK, `{"data":{"id":"a1b2c3","type":"accounts","version":0}}`, nil), nil
+		}
+		deletes++
+		return mockedResponse(http.StatusConflict, `{"error_message":"invalid version"}`, nil), nil
+	})
+	client, err := NewRestClient(mockedHttpClient, NewRestClientParams{BaseUrl: baseFakeUrl})
+	service := NewAccountsService(client)
+
+	resp, err := service.DeleteLatest(context.Background(), "a1b2c3", WithMaxAttempts(5))
+
+	assert.Equal(t, 5, deletes, "Delete attempts incorrect")
+	assert.Equal(t, http.StatusConflict, resp.StatusCode, "Response code incorrect")
+	var conflictErr *VersionConflictError
+	if assert.ErrorAs(t, err, &conflictErr, "Error type incorrect") {
+		assert.Equal(t, "a1b2c3", conflictErr.AccountID, "conflictErr.AccountID incorrect")
+		assert.Equal(t, 5, conflictErr.Attempts, "conflictErr.Attempts incorrect")
+	}
+	assert.Equal(t, "record a1b2c3 version kept changing after 5 attempts: invalid version", err.Error(), "Error message expected")
+}
+
+func TestAccountsService_DeleteLatest_notFound(t *testing.T) {
+	mockedHttpClient := mockedHttpClientHandler(func(req *http.Request) (*http.Response, error) {
+		return mockedResponse(http.StatusNotFound, `{"error_message":"record a1b2c3 does not exist"}`, nil), nil
+	})
+	client, err := NewRestClient(mockedHttpClient, NewRestClientParams{BaseUrl: baseFakeUrl})
+	service := NewAccountsService(client)
+
+	resp, err := service.DeleteLatest(context.Background(), "a1b2c3")
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode, "Response code incorrect")
+	assert.Equal(t, "record a1b2c3 does not exist", err.Error(), "Error message expected")
+
+	resp, err = service.DeleteLatest(context.Background(), "a1b2c3", WithNotFoundAsSuccess())
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode, "Response code incorrect")
+}
+
+func TestAccountsService_DeleteLatest_deletedInBetween(t *testing.T) {
+	mockedHttpClient := mockedHttpClientHandler(func(req *http.Request) (*http.Response, error) {
+		if req.Method == "GET" {
+			return mockedResponse(http.StatusOK, `{"data":{"id":"a1b2c3","type":"accounts","version":0}}`, nil), nil
+		}
+		return mockedResponse(http.StatusNotFound, "", nil), nil
+	})
+	client, err := NewRestClient(mockedHttpClient, NewRestClientParams{BaseUrl: baseFakeUrl})
+	service := NewAccountsService(client)
+
+	_, err = service.DeleteLatest(context.Background(), "a1b2c3")
+	assert.Equal(t, "record a1b2c3 does not exist", err.Error(), "Error message expected")
+
+	_, err = service.DeleteLatest(context.Background(), "a1b2c3", WithNotFoundAsSuccess())
+	assert.Nil(t, err, "Error should be nil")
+}
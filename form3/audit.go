@@ -0,0 +1,75 @@
+package form3
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+const auditBasePath = "audit/entries"
+
+// NewAuditService returns a AuditService instance.
+func NewAuditService(client *RestClient) *AuditService {
+	return &AuditService{
+		service{
+			client,
+		},
+	}
+}
+
+// List retrieves a page of audit entries recorded for the given record type and id
+func (s *AuditService) List(ctx context.Context, recordType string, id string, listOpts *ListOptions, opts ...CallOption) ([]AuditEntry, *RestClientResponse, error) {
+	path := fmt.Sprintf("%s/%s/%s", auditBasePath, recordType, id)
+
+	req, err := s.client.GetRequest(listPath(path, listOpts), opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var entries []AuditEntry
+	resp, err := s.client.Do(ctx, req.Request, &entries, opts...)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return entries, resp, nil
+}
+
+// ListForAccount retrieves a page of audit entries recorded for an account
+func (s *AuditService) ListForAccount(ctx context.Context, id string, listOpts *ListOptions, opts ...CallOption) ([]AuditEntry, *RestClientResponse, error) {
+	return s.List(ctx, string(AcctTypeAccounts), id, listOpts, opts...)
+}
+
+// Accounts decodes the before and after snapshots of an entry recorded for an account.
+// A snapshot is nil when it is not present, e.g. before data of a create action.
+func (e *AuditEntry) Accounts() (before *Account, after *Account, err error) {
+	if e.Attributes == nil {
+		return nil, nil, nil
+	}
+	if e.Attributes.RecordType != "" && e.Attributes.RecordType != string(AcctTypeAccounts) {
+		return nil, nil, errors.New("audit entry is not recorded for an account")
+	}
+
+	if before, err = decodeAccountSnapshot(e.Attributes.BeforeData); err != nil {
+		return nil, nil, err
+	}
+	if after, err = decodeAccountSnapshot(e.Attributes.AfterData); err != nil {
+		return nil, nil, err
+	}
+
+	return before, after, nil
+}
+
+func decodeAccountSnapshot(data json.RawMessage) (*Account, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+
+	account := new(Account)
+	if err := json.Unmarshal(data, account); err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
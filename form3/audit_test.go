@@ -0,0 +1,65 @@
+package form3
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestAuditService_ListForAccount(t *testing.T) {
+	mockedHttpClient := mockedHttpClientHandler(func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, "GET", req.Method)
+		assert.Equal(t, fmt.Sprintf("%s/%s", baseFakeUrl, "audit/entries/accounts/a1b2c3?page%5Bnumber%5D=2&page%5Bsize%5D=1"), req.URL.String())
+
+		body := `{"data":[{"id":"e1","organisation_id":"b","type":"audit_entries","attributes":{
+			"action":"update","action_time":"2023-03-01T10:00:00Z","actor_name":"jane","record_type":"accounts","record_id":"a1b2c3",
+			"before_data":{"id":"a1b2c3","type":"accounts","version":0,"attributes":{"status":"pending"}},
+			"after_data":{"id":"a1b2c3","type":"accounts","version":1,"attributes":{"status":"confirmed"}}}}]}`
+		return mockedResponse(http.StatusOK, body, nil), nil
+	})
+	client, err := NewRestClient(mockedHttpClient, NewRestClientParams{BaseUrl: baseFakeUrl})
+	service := NewAuditService(client)
+
+	entries, resp, err := service.ListForAccount(context.Background(), "a1b2c3", &ListOptions{PageNumber: 2, PageSize: 1})
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "Response code incorrect")
+	assert.Len(t, entries, 1, "Entries length incorrect")
+
+	entry := entries[0]
+	assert.Equal(t, AuditActionUpdate, entry.Attributes.Action, "entry.Attributes.Action incorrect")
+	assert.Equal(t, "jane", entry.Attributes.ActorName, "entry.Attributes.ActorName incorrect")
+	assert.Equal(t, time.Date(2023, 3, 1, 10, 0, 0, 0, time.UTC), *entry.Attributes.ActionTime, "entry.Attributes.ActionTime incorrect")
+
+	before, after, err := entry.Accounts()
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, AcctStatusPending, before.Attributes.Status, "before.Attributes.Status incorrect")
+	assert.Equal(t, AcctStatusConfirmed, after.Attributes.Status, "after.Attributes.Status incorrect")
+	assert.Equal(t, 1, after.Version, "after.Version incorrect")
+}
+
+func TestAuditEntry_Accounts_createAction(t *testing.T) {
+	entry := &AuditEntry{Attributes: &AuditEntryAttributes{
+		Action:     AuditActionCreate,
+		RecordType: "accounts",
+		AfterData:  []byte(`{"id":"a1b2c3","type":"accounts","version":0}`),
+	}}
+
+	before, after, err := entry.Accounts()
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Nil(t, before, "Before snapshot should be nil")
+	assert.Equal(t, "a1b2c3", after.ID, "after.ID incorrect")
+}
+
+func TestAuditEntry_Accounts_otherRecordType(t *testing.T) {
+	entry := &AuditEntry{Attributes: &AuditEntryAttributes{RecordType: "mandates"}}
+
+	_, _, err := entry.Accounts()
+
+	assert.NotNil(t, err, "Error should be not nil")
+}
@@ -0,0 +1,78 @@
+package form3
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// CallOption customises a single call of a service, see the With functions
+type CallOption func(*callConfig)
+
+// ResponseHook is called with the response of a call once it is decoded, even when it returns an error
+type ResponseHook func(resp *RestClientResponse, err error)
+
+type callConfig struct {
+	header        http.Header
+	query         url.Values
+	timeout       time.Duration
+	retryPolicy   *RetryPolicy
+	responseHooks []ResponseHook
+}
+
+func newCallConfig(opts []CallOption) *callConfig {
+	config := &callConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	return config
+}
+
+// WithHeader sets a header on the request
+func WithHeader(key string, value string) CallOption {
+	return func(c *callConfig) {
+		if c.header == nil {
+			c.header = make(http.Header)
+		}
+		c.header.Set(key, value)
+	}
+}
+
+// WithIdempotencyKey sets the Idempotency-Key header, making the request safe to retry
+func WithIdempotencyKey(key string) CallOption {
+	return WithHeader(idempotencyKeyHeader, key)
+}
+
+// WithQuery adds a query parameter to the request URL
+func WithQuery(key string, value string) CallOption {
+	return func(c *callConfig) {
+		if c.query == nil {
+			c.query = make(url.Values)
+		}
+		c.query.Add(key, value)
+	}
+}
+
+// WithTimeout limits the duration of the call, retries included
+func WithTimeout(timeout time.Duration) CallOption {
+	return func(c *callConfig) {
+		c.timeout = timeout
+	}
+}
+
+// WithRetryPolicy overrides the retry policy of the RestClient for the call
+func WithRetryPolicy(policy RetryPolicy) CallOption {
+	return func(c *callConfig) {
+		c.retryPolicy = &policy
+	}
+}
+
+// WithResponseHook registers a hook called with the response of the call
+func WithResponseHook(hook ResponseHook) CallOption {
+	return func(c *callConfig) {
+		c.responseHooks = append(c.responseHooks, hook)
+	}
+}
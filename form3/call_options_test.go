@@ -0,0 +1,90 @@
+package form3
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestAccountsService_Get_callOptions(t *testing.T) {
+	mockedHttpClient := mockedHttpClientHandler(func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, "https://www.fake-api.com/v1/organisation/accounts/a1b2c3?include=audit", req.URL.String())
+		assert.Equal(t, "trace-1", req.Header.Get("X-Request-Id"))
+		assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+
+		return mockedResponse(http.StatusOK, `{"data":{"id":"a1b2c3"}}`, nil), nil
+	})
+	client, _ := NewRestClient(mockedHttpClient, NewRestClientParams{BaseUrl: baseFakeUrl})
+	service := NewAccountsService(client)
+
+	var hookResp *RestClientResponse
+	account, _, err := service.Get(context.Background(), "a1b2c3",
+		WithHeader("X-Request-Id", "trace-1"),
+		WithQuery("include", "audit"),
+		WithResponseHook(func(resp *RestClientResponse, err error) { hookResp = resp }),
+	)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, "a1b2c3", account.ID, "account.ID incorrect")
+	assert.Equal(t, http.StatusOK, hookResp.StatusCode, "Hook should receive the response")
+}
+
+func TestAccountsService_Delete_queryOptionKeepsVersion(t *testing.T) {
+	mockedHttpClient := mockedHttpClientHandler(func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, "1", req.URL.Query().Get("version"))
+		assert.Equal(t, "true", req.URL.Query().Get("dry_run"))
+
+		return mockedResponse(http.StatusNoContent, "", nil), nil
+	})
+	client, _ := NewRestClient(mockedHttpClient, NewRestClientParams{BaseUrl: baseFakeUrl})
+
+	_, err := NewAccountsService(client).Delete(context.Background(), "a1b2c3", 1, WithQuery("dry_run", "true"))
+
+	assert.Nil(t, err, "Error should be nil")
+}
+
+func TestAccountsService_Create_idempotencyKey(t *testing.T) {
+	mockedHttpClient := mockedHttpClientHandler(func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, "key-1", req.Header.Get("Idempotency-Key"))
+
+		return mockedResponse(http.StatusCreated, `{"data":{"id":"a1b2c3"}}`, nil), nil
+	})
+	client, _ := NewRestClient(mockedHttpClient, NewRestClientParams{BaseUrl: baseFakeUrl})
+
+	_, _, err := NewAccountsService(client).Create(context.Background(), &Account{ID: "a1b2c3"}, WithIdempotencyKey("key-1"))
+
+	assert.Nil(t, err, "Error should be nil")
+}
+
+func TestRestClient_Do_withTimeout(t *testing.T) {
+	mockedHttpClient := mockedHttpClientHandler(func(req *http.Request) (*http.Response, error) {
+		deadline, ok := req.Context().Deadline()
+		assert.True(t, ok, "Request context should have a deadline")
+		assert.WithinDuration(t, time.Now().Add(50*time.Millisecond), deadline, 50*time.Millisecond)
+
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	})
+	client, _ := NewRestClient(mockedHttpClient, NewRestClientParams{BaseUrl: baseFakeUrl})
+
+	_, resp, err := NewAccountsService(client).Get(context.Background(), "a1b2c3", WithTimeout(50*time.Millisecond))
+
+	assert.Nil(t, resp, "Response should be nil")
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "Error should be a deadline exceeded")
+}
+
+func TestRestClient_Do_responseHookOnError(t *testing.T) {
+	mockedHttpClient := mockedHttpClientHandler(func(req *http.Request) (*http.Response, error) {
+		return mockedResponse(http.StatusNotFound, `{"error_message":"record a1b2c3 does not exist"}`, nil), nil
+	})
+	client, _ := NewRestClient(mockedHttpClient, NewRestClientParams{BaseUrl: baseFakeUrl})
+
+	var hookErr error
+	_, _, err := NewAccountsService(client).Get(context.Background(), "a1b2c3",
+		WithResponseHook(func(resp *RestClientResponse, err error) { hookErr = err }))
+
+	assert.Equal(t, err, hookErr, "Hook should receive the error")
+}
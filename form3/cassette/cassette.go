@@ -0,0 +1,221 @@
+// Package cassette provides HttpClient implementations that record real API interactions
+// into cassette files and replay them in tests.
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const (
+	redactedValue         = "REDACTED"
+	uuidPlaceholderPrefix = "00000000-0000-0000-0000-"
+)
+
+var uuidRegexp = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+
+// Options configures how interactions are sanitised before being stored or matched
+type Options struct {
+	// RedactFields are JSON member names whose values are replaced in request and response bodies
+	RedactFields []string
+	// RedactHeaders are header names whose values are replaced. Authorization is always redacted
+	RedactHeaders []string
+	// NormaliseUUIDs replaces every UUID with a deterministic placeholder in order of appearance
+	NormaliseUUIDs bool
+}
+
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+type Request struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Load reads a cassette file
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cassette := new(Cassette)
+	if err = json.Unmarshal(data, cassette); err != nil {
+		return nil, err
+	}
+
+	return cassette, nil
+}
+
+// Save writes the cassette to a file
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
+
+// sanitiser applies the Options to bodies and headers, keeping the UUID placeholders
+// assigned so far so a value is always replaced by the same placeholder
+type sanitiser struct {
+	opts         Options
+	placeholders map[string]string
+	originals    map[string]string
+}
+
+func newSanitiser(opts Options) *sanitiser {
+	return &sanitiser{
+		opts:         opts,
+		placeholders: map[string]string{},
+		originals:    map[string]string{},
+	}
+}
+
+func (s *sanitiser) body(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+
+	// the body is only re-encoded when a member is redacted, numbers and escapes are kept as they are
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var decoded any
+	if err := decoder.Decode(&decoded); err == nil && !decoder.More() && s.redact(decoded) {
+		var encoded bytes.Buffer
+		encoder := json.NewEncoder(&encoded)
+		encoder.SetEscapeHTML(false)
+		if err = encoder.Encode(decoded); err == nil {
+			data = bytes.TrimSuffix(encoded.Bytes(), []byte("\n"))
+		}
+	}
+
+	return s.normalise(string(data))
+}
+
+// redact replaces the values of the redacted members, reporting whether there was any
+func (s *sanitiser) redact(value any) bool {
+	redacted := false
+	switch v := value.(type) {
+	case map[string]any:
+		for key, member := range v {
+			if s.isRedactedField(key) {
+				v[key] = redactedValue
+				redacted = true
+			} else if s.redact(member) {
+				redacted = true
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if s.redact(item) {
+				redacted = true
+			}
+		}
+	}
+
+	return redacted
+}
+
+func (s *sanitiser) isRedactedField(name string) bool {
+	for _, field := range s.opts.RedactFields {
+		if field == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *sanitiser) header(header http.Header) http.Header {
+	if len(header) == 0 {
+		return nil
+	}
+
+	sanitised := header.Clone()
+	for _, name := range append([]string{"Authorization"}, s.opts.RedactHeaders...) {
+		if sanitised.Get(name) != "" {
+			sanitised.Set(name, redactedValue)
+		}
+	}
+
+	return sanitised
+}
+
+func (s *sanitiser) normalise(text string) string {
+	if !s.opts.NormaliseUUIDs {
+		return text
+	}
+
+	return uuidRegexp.ReplaceAllStringFunc(text, func(id string) string {
+		if placeholder, ok := s.placeholders[id]; ok {
+			return placeholder
+		}
+
+		placeholder := fmt.Sprintf("%s%012d", uuidPlaceholderPrefix, len(s.placeholders)+1)
+		s.placeholders[id] = placeholder
+		s.originals[placeholder] = id
+		return placeholder
+	})
+}
+
+// denormalise restores the original UUIDs of the placeholders seen by this sanitiser.
+// Placeholders not seen yet belong to UUIDs generated by the API, they are kept as they are
+// and registered so later requests referring to them are normalised to the same placeholder.
+func (s *sanitiser) denormalise(text string) string {
+	if !s.opts.NormaliseUUIDs {
+		return text
+	}
+
+	return uuidRegexp.ReplaceAllStringFunc(text, func(placeholder string) string {
+		if id, ok := s.originals[placeholder]; ok {
+			return id
+		}
+		if strings.HasPrefix(placeholder, uuidPlaceholderPrefix) {
+			s.placeholders[placeholder] = placeholder
+			s.originals[placeholder] = placeholder
+		}
+		return placeholder
+	})
+}
+
+// readBody reads the request body and restores it so the request can still be sent
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	data, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(data))
+
+	return data, nil
+}
+
+func requestPath(req *http.Request) string {
+	return req.URL.RequestURI()
+}
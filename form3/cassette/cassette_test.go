@@ -0,0 +1,170 @@
+package cassette
+
+import (
+	"context"
+	"encoding/json"
+	"form3-interview-accountapi/form3"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newFakeAPI returns a server storing the created accounts and echoing them back
+func newFakeAPI(t *testing.T) *httptest.Server {
+	accounts := map[string]string{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, "/v1/organisation/accounts/")
+		switch r.Method {
+		case http.MethodPost:
+			body, _ := io.ReadAll(r.Body)
+			payload := struct {
+				Data map[string]any `json:"data"`
+			}{}
+			_ = json.Unmarshal(body, &payload)
+			payload.Data["version"] = 0
+			data, _ := json.Marshal(payload)
+			accounts[payload.Data["id"].(string)] = string(data)
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write(data)
+		case http.MethodGet:
+			if account, ok := accounts[id]; ok {
+				_, _ = w.Write([]byte(account))
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error_message":"record ` + id + ` does not exist"}`))
+		case http.MethodDelete:
+			delete(accounts, id)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func runScenario(t *testing.T, httpClient form3.HttpClient, baseUrl string) {
+	t.Helper()
+
+	client, err := form3.NewRestClient(httpClient, form3.NewRestClientParams{BaseUrl: baseUrl})
+	if err != nil {
+		t.Fatalf("Error creating RestClient: %v", err)
+	}
+	service := form3.NewAccountsService(client)
+	ctx := context.Background()
+
+	account := &form3.Account{
+		ID:             uuid.New().String(),
+		OrganisationID: uuid.New().String(),
+		Type:           form3.AcctTypeAccounts,
+		Attributes: &form3.AccountAttributes{
+			Country: form3.CountryCodeBelgium,
+			Iban:    "BE71096123456769",
+		},
+	}
+
+	newAccount, _, err := service.Create(ctx, account)
+	assert.Nil(t, err, "Create error should be nil")
+	assert.Equal(t, account.ID, newAccount.ID, "newAccount.ID incorrect")
+	assert.Equal(t, account.OrganisationID, newAccount.OrganisationID, "newAccount.OrganisationID incorrect")
+
+	retrieved, _, err := service.Get(ctx, newAccount.ID)
+	assert.Nil(t, err, "Get error should be nil")
+	assert.Equal(t, account.ID, retrieved.ID, "retrieved.ID incorrect")
+
+	resp, err := service.Delete(ctx, account.ID, 0)
+	assert.Nil(t, err, "Delete error should be nil")
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode, "Delete response code incorrect")
+
+	_, resp, err = service.Get(ctx, account.ID)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode, "Get response code incorrect")
+	assert.Equal(t, "record "+account.ID+" does not exist", err.Error(), "Error message expected")
+}
+
+func TestRecorder_Replayer(t *testing.T) {
+	server := newFakeAPI(t)
+	opts := Options{RedactFields: []string{"iban"}, NormaliseUUIDs: true}
+	path := filepath.Join(t.TempDir(), "accounts.json")
+
+	recorder := NewRecorder(nil, opts)
+	runScenario(t, recorder, server.URL+"/v1")
+	if err := recorder.Save(path); err != nil {
+		t.Fatalf("Error saving cassette: %v", err)
+	}
+
+	replayer, err := LoadReplayer(path, opts)
+	if err != nil {
+		t.Fatalf("Error loading cassette: %v", err)
+	}
+	// the replayed scenario uses new UUIDs and no server
+	runScenario(t, replayer, "http://replay.invalid/v1")
+
+	assert.Equal(t, 0, replayer.Unused(), "All interactions should be served")
+}
+
+func TestRecorder_sanitisesInteractions(t *testing.T) {
+	server := newFakeAPI(t)
+	recorder := NewRecorder(nil, Options{RedactFields: []string{"iban"}, NormaliseUUIDs: true})
+
+	runScenario(t, recorder, server.URL+"/v1")
+	cassette := recorder.Cassette()
+
+	assert.Len(t, cassette.Interactions, 4, "Interactions length incorrect")
+	created := cassette.Interactions[0]
+	assert.Equal(t, "/v1/organisation/accounts", created.Request.Path, "Request path incorrect")
+	assert.Equal(t,
+		`{"data":{"attributes":{"country":"BE","iban":"REDACTED"},"id":"00000000-0000-0000-0000-000000000001","organisation_id":"00000000-0000-0000-0000-000000000002","type":"accounts","version":0}}`,
+		created.Request.Body, "Request body incorrect")
+	assert.Equal(t, "/v1/organisation/accounts/00000000-0000-0000-0000-000000000001", cassette.Interactions[1].Request.Path, "Request path incorrect")
+	assert.Equal(t, "/v1/organisation/accounts/00000000-0000-0000-0000-000000000001?version=0", cassette.Interactions[2].Request.Path, "Request path incorrect")
+}
+
+func TestSanitiser_body(t *testing.T) {
+	s := newSanitiser(Options{RedactFields: []string{"iban"}})
+
+	assert.Equal(t, `{"data":{"attributes":{"iban":"REDACTED","name":["A<B> & C"]},"number":9007199254740993}}`,
+		s.body([]byte(`{"data": {"number": 9007199254740993, "attributes": {"name": ["A<B> & C"], "iban": "BE71096123456769"}}}`)),
+		"redacted body incorrect")
+	assert.Equal(t, `{"data": {"number": 9007199254740993, "name": "A<B> & C"}}`,
+		s.body([]byte(`{"data": {"number": 9007199254740993, "name": "A<B> & C"}}`)),
+		"body without redacted member should be kept as is")
+}
+
+func TestReplayer_unmatchedRequest(t *testing.T) {
+	replayer := NewReplayer(&Cassette{}, Options{})
+	req, _ := http.NewRequest(http.MethodGet, "http://replay.invalid/v1/organisation/accounts/1", nil)
+
+	resp, err := replayer.Do(req)
+
+	assert.Nil(t, resp, "Response should be nil")
+	assert.Equal(t, "cassette: no interaction recorded for GET /v1/organisation/accounts/1", err.Error(), "Error message expected")
+}
+
+func TestRecorder_redactsHeaders(t *testing.T) {
+	httpClient := mockedHttpClientHandler(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{"X-Api-Key": {"secret"}}, Body: http.NoBody}, nil
+	})
+	recorder := NewRecorder(httpClient, Options{RedactHeaders: []string{"X-Api-Key"}})
+	req, _ := http.NewRequest(http.MethodGet, "http://replay.invalid/v1/foo", nil)
+	req.Header.Set("Authorization", "Bearer token")
+
+	_, err := recorder.Do(req)
+
+	assert.Nil(t, err, "Error should be nil")
+	interaction := recorder.Cassette().Interactions[0]
+	assert.Equal(t, "REDACTED", interaction.Request.Header.Get("Authorization"), "Authorization header should be redacted")
+	assert.Equal(t, "REDACTED", interaction.Response.Header.Get("X-Api-Key"), "X-Api-Key header should be redacted")
+	assert.Equal(t, "Bearer token", req.Header.Get("Authorization"), "Sent request should keep its headers")
+}
+
+type mockedHttpClientHandler func(req *http.Request) (*http.Response, error)
+
+func (handler mockedHttpClientHandler) Do(req *http.Request) (*http.Response, error) {
+	return handler(req)
+}
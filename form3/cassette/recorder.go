@@ -0,0 +1,88 @@
+package cassette
+
+import (
+	"bytes"
+	"form3-interview-accountapi/form3"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// Recorder is a form3.HttpClient that sends the requests through a real client and
+// records every request/response pair, sanitised as configured in Options
+type Recorder struct {
+	client    form3.HttpClient
+	sanitiser *sanitiser
+	cassette  *Cassette
+	mu        sync.Mutex
+}
+
+// NewRecorder returns a Recorder instance.
+// If a httpClient is not provided, a default http.Client will be assigned
+func NewRecorder(httpClient form3.HttpClient, opts Options) *Recorder {
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	}
+
+	return &Recorder{
+		client:    httpClient,
+		sanitiser: newSanitiser(opts),
+		cassette:  &Cassette{},
+	}
+}
+
+// Do sends the request and records the interaction
+func (r *Recorder) Do(req *http.Request) (*http.Response, error) {
+	reqBody, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	if len(respBody) == 0 {
+		resp.Body = http.NoBody
+	} else {
+		resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request: Request{
+			Method: req.Method,
+			Path:   r.sanitiser.normalise(requestPath(req)),
+			Header: r.sanitiser.header(req.Header),
+			Body:   r.sanitiser.body(reqBody),
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     r.sanitiser.header(resp.Header),
+			Body:       r.sanitiser.body(respBody),
+		},
+	})
+
+	return resp, nil
+}
+
+// Cassette returns the interactions recorded so far
+func (r *Recorder) Cassette() *Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return &Cassette{Interactions: append([]Interaction(nil), r.cassette.Interactions...)}
+}
+
+// Save writes the interactions recorded so far to a cassette file
+func (r *Recorder) Save(path string) error {
+	return r.Cassette().Save(path)
+}
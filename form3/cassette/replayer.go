@@ -0,0 +1,99 @@
+package cassette
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// Replayer is a form3.HttpClient that serves the responses stored in a cassette.
+// Requests are matched on method, path and body; each interaction is served once, in recording order.
+type Replayer struct {
+	sanitiser *sanitiser
+	cassette  *Cassette
+	used      []bool
+	mu        sync.Mutex
+}
+
+// NewReplayer returns a Replayer serving the interactions of the cassette.
+// opts must be the same used when the cassette was recorded
+func NewReplayer(cassette *Cassette, opts Options) *Replayer {
+	return &Replayer{
+		sanitiser: newSanitiser(opts),
+		cassette:  cassette,
+		used:      make([]bool, len(cassette.Interactions)),
+	}
+}
+
+// LoadReplayer returns a Replayer serving the interactions of a cassette file.
+func LoadReplayer(path string, opts Options) (*Replayer, error) {
+	cassette, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewReplayer(cassette, opts), nil
+}
+
+// Do returns the recorded response of the first unused interaction matching the request
+func (r *Replayer) Do(req *http.Request) (*http.Response, error) {
+	reqBody, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	path := r.sanitiser.normalise(requestPath(req))
+	body := r.sanitiser.body(reqBody)
+
+	for i, interaction := range r.cassette.Interactions {
+		if r.used[i] || interaction.Request.Method != req.Method ||
+			interaction.Request.Path != path || interaction.Request.Body != body {
+			continue
+		}
+		r.used[i] = true
+
+		return r.response(req, interaction.Response), nil
+	}
+
+	return nil, fmt.Errorf("cassette: no interaction recorded for %s %s", req.Method, path)
+}
+
+// Unused returns the number of interactions not served yet
+func (r *Replayer) Unused() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	unused := 0
+	for _, used := range r.used {
+		if !used {
+			unused++
+		}
+	}
+
+	return unused
+}
+
+func (r *Replayer) response(req *http.Request, recorded Response) *http.Response {
+	header := recorded.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+
+	resp := &http.Response{
+		Status:     fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode: recorded.StatusCode,
+		Header:     header,
+		Request:    req,
+		Body:       http.NoBody,
+	}
+	if recorded.Body != "" {
+		resp.Body = io.NopCloser(bytes.NewBufferString(r.sanitiser.denormalise(recorded.Body)))
+	}
+
+	return resp
+}
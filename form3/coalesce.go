@@ -0,0 +1,167 @@
+package form3
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CoalescingStats counts the GET requests handled by the coalescing of a RestClient
+type CoalescingStats struct {
+	// Requests is the number of GET requests sent through Do
+	Requests int64
+	// Deduplicated is the number of them served by a request already in flight
+	Deduplicated int64
+}
+
+// coalescer shares one in-flight request between concurrent identical GET requests
+type coalescer struct {
+	mu           sync.Mutex
+	flights      map[string]*flight
+	requests     atomic.Int64
+	deduplicated atomic.Int64
+}
+
+// flight is a request in progress and its outcome once done is closed
+type flight struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	waiters int
+
+	resp *http.Response
+	body []byte
+	err  error
+}
+
+func newCoalescer(enabled bool) *coalescer {
+	if !enabled {
+		return nil
+	}
+
+	return &coalescer{flights: map[string]*flight{}}
+}
+
+// CoalescingStats returns the counters of the GET requests coalescing, zero when it is disabled
+func (c *RestClient) CoalescingStats() CoalescingStats {
+	if c.coalescer == nil {
+		return CoalescingStats{}
+	}
+
+	return CoalescingStats{
+		Requests:     c.coalescer.requests.Load(),
+		Deduplicated: c.coalescer.deduplicated.Load(),
+	}
+}
+
+// sendCoalesced sends the request unless an identical one is in flight, and waits for the shared response.
+// The request runs until every waiter has its response or has given up, so a caller cancelling its
+// ctx does not fail the others. It keeps the values and the deadline of the ctx of the caller sending it.
+// Each waiter gets its own copy of the response headers and body.
+func (c *RestClient) sendCoalesced(ctx context.Context, req *http.Request, policy *RetryPolicy) (*http.Response, error) {
+	co := c.coalescer
+	key := coalesceKey(req)
+
+	co.mu.Lock()
+	co.requests.Add(1)
+	f, ok := co.flights[key]
+	if ok {
+		f.waiters++
+		co.deduplicated.Add(1)
+	} else {
+		var flightCtx context.Context = detachedContext{ctx}
+		var cancel context.CancelFunc
+		if deadline, ok := ctx.Deadline(); ok {
+			flightCtx, cancel = context.WithDeadline(flightCtx, deadline)
+		} else {
+			flightCtx, cancel = context.WithCancel(flightCtx)
+		}
+		f = &flight{done: make(chan struct{}), cancel: cancel, waiters: 1}
+		co.flights[key] = f
+		go c.fly(flightCtx, key, f, req, policy)
+	}
+	co.mu.Unlock()
+
+	select {
+	case <-f.done:
+	case <-ctx.Done():
+		co.leave(key, f)
+		return nil, ctx.Err()
+	}
+
+	if f.err != nil {
+		return nil, f.err
+	}
+	resp := *f.resp
+	resp.Header = f.resp.Header.Clone()
+	resp.Trailer = f.resp.Trailer.Clone()
+	resp.Body = io.NopCloser(bytes.NewReader(f.body))
+
+	return &resp, nil
+}
+
+func (c *RestClient) fly(ctx context.Context, key string, f *flight, req *http.Request, policy *RetryPolicy) {
+	defer f.cancel()
+
+	f.resp, f.err = c.roundTrip(ctx, req, policy)
+	if f.err == nil {
+		f.body, f.err = io.ReadAll(http.MaxBytesReader(nil, f.resp.Body, c.maxResponseSize))
+		f.resp.Body.Close()
+	}
+
+	co := c.coalescer
+	co.mu.Lock()
+	if co.flights[key] == f {
+		delete(co.flights, key)
+	}
+	co.mu.Unlock()
+	close(f.done)
+}
+
+// leave removes a waiter of the flight, the request is cancelled when none is left
+func (co *coalescer) leave(key string, f *flight) {
+	co.mu.Lock()
+	defer co.mu.Unlock()
+
+	f.waiters--
+	if f.waiters > 0 {
+		return
+	}
+	if co.flights[key] == f {
+		delete(co.flights, key)
+	}
+	f.cancel()
+}
+
+// detachedContext keeps the values of its parent but not its cancellation nor deadline, like
+// context.WithoutCancel of Go 1.21
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
+func (c detachedContext) Value(key any) any         { return c.parent.Value(key) }
+
+// coalesceKey identifies identical requests by their URL and headers
+func coalesceKey(req *http.Request) string {
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var key strings.Builder
+	key.WriteString(req.Method + " " + req.URL.String())
+	for _, name := range names {
+		key.WriteString("\n" + name + ": " + strings.Join(req.Header[name], ", "))
+	}
+
+	return key.String()
+}
@@ -0,0 +1,195 @@
+package form3
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// blockingHttpClient answers every request with an account once released, counting the requests received
+type blockingHttpClient struct {
+	release  chan struct{}
+	requests atomic.Int64
+	canceled atomic.Int64
+}
+
+func newBlockingHttpClient() *blockingHttpClient {
+	return &blockingHttpClient{release: make(chan struct{})}
+}
+
+func (c *blockingHttpClient) Do(req *http.Request) (*http.Response, error) {
+	c.requests.Add(1)
+	select {
+	case <-c.release:
+		return mockedResponse(http.StatusOK, `{"data":{"id":"ad27e265-9605-4b4b-a0e5-3003ea9cc4dc","version":2}}`, nil), nil
+	case <-req.Context().Done():
+		c.canceled.Add(1)
+		return nil, req.Context().Err()
+	}
+}
+
+func waitForStats(t *testing.T, client *RestClient, requests int64) {
+	t.Helper()
+	assert.Eventually(t, func() bool {
+		return client.CoalescingStats().Requests == requests
+	}, time.Second, time.Millisecond, "requests were not received")
+}
+
+func TestAccountsService_Get_coalesced(t *testing.T) {
+	httpClient := newBlockingHttpClient()
+	client, _ := NewRestClient(httpClient, NewRestClientParams{BaseUrl: baseFakeUrl, CoalesceGets: true})
+	service := NewAccountsService(client)
+
+	const callers = 10
+	accounts := make([]*Account, callers)
+	errs := make([]error, callers)
+	wg := sync.WaitGroup{}
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			accounts[i], _, errs[i] = service.Get(context.Background(), "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+		}(i)
+	}
+	waitForStats(t, client, callers)
+	close(httpClient.release)
+	wg.Wait()
+
+	assert.Equal(t, int64(1), httpClient.requests.Load(), "requests sent incorrect")
+	assert.Equal(t, CoalescingStats{Requests: callers, Deduplicated: callers - 1}, client.CoalescingStats(), "stats incorrect")
+	for i := 0; i < callers; i++ {
+		assert.Nil(t, errs[i], "Get error should be nil")
+		assert.Equal(t, 2, accounts[i].Version, "Version incorrect")
+	}
+	assert.NotSame(t, accounts[0], accounts[1], "callers should get their own account")
+}
+
+type coalesceTestKey struct{}
+
+func TestRestClient_coalescedRequestContext(t *testing.T) {
+	var sent context.Context
+	mockedHttpClient := mockedHttpClientHandler(func(req *http.Request) (*http.Response, error) {
+		sent = req.Context()
+		return mockedResponse(http.StatusOK, `{"data":{"id":"ad27e265-9605-4b4b-a0e5-3003ea9cc4dc","version":2}}`, nil), nil
+	})
+	client, _ := NewRestClient(mockedHttpClient, NewRestClientParams{BaseUrl: baseFakeUrl, CoalesceGets: true})
+	deadline := time.Now().Add(time.Minute)
+	ctx, cancel := context.WithDeadline(context.WithValue(context.Background(), coalesceTestKey{}, "trace"), deadline)
+	defer cancel()
+	req, _ := client.GetRequest("organisation/accounts/ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+
+	_, err := client.sendCoalesced(ctx, req.Request, nil)
+
+	assert.Nil(t, err, "error should be nil")
+	assert.Equal(t, "trace", sent.Value(coalesceTestKey{}), "context values should be kept")
+	sentDeadline, ok := sent.Deadline()
+	assert.True(t, ok, "deadline should be kept")
+	assert.Equal(t, deadline, sentDeadline, "deadline incorrect")
+}
+
+func TestAccountsService_Get_coalescedHeadersCopied(t *testing.T) {
+	httpClient := newBlockingHttpClient()
+	client, _ := NewRestClient(httpClient, NewRestClientParams{BaseUrl: baseFakeUrl, CoalesceGets: true})
+	service := NewAccountsService(client)
+
+	resps := make([]*RestClientResponse, 2)
+	wg := sync.WaitGroup{}
+	for i := range resps {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, resps[i], _ = service.Get(context.Background(), "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+		}(i)
+	}
+	waitForStats(t, client, 2)
+	close(httpClient.release)
+	wg.Wait()
+	resps[0].Header.Set("X-Changed", "true")
+
+	assert.Equal(t, int64(1), httpClient.requests.Load(), "requests sent incorrect")
+	assert.Empty(t, resps[1].Header.Get("X-Changed"), "headers should be copied for each waiter")
+}
+
+func TestAccountsService_Get_coalescedWaiterCanceled(t *testing.T) {
+	httpClient := newBlockingHttpClient()
+	client, _ := NewRestClient(httpClient, NewRestClientParams{BaseUrl: baseFakeUrl, CoalesceGets: true})
+	service := NewAccountsService(client)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	canceledErr := make(chan error)
+	go func() {
+		_, _, err := service.Get(ctx, "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+		canceledErr <- err
+	}()
+	waitForStats(t, client, 1)
+
+	var account *Account
+	var err error
+	done := make(chan struct{})
+	go func() {
+		account, _, err = service.Get(context.Background(), "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+		close(done)
+	}()
+	waitForStats(t, client, 2)
+
+	cancel()
+	assert.ErrorIs(t, <-canceledErr, context.Canceled, "canceled waiter error incorrect")
+	close(httpClient.release)
+	<-done
+
+	assert.Nil(t, err, "Get error should be nil")
+	assert.Equal(t, 2, account.Version, "Version incorrect")
+	assert.Equal(t, int64(0), httpClient.canceled.Load(), "shared request should not be canceled")
+}
+
+func TestAccountsService_Get_coalescedAllWaitersCanceled(t *testing.T) {
+	httpClient := newBlockingHttpClient()
+	client, _ := NewRestClient(httpClient, NewRestClientParams{BaseUrl: baseFakeUrl, CoalesceGets: true})
+	service := NewAccountsService(client)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, _, err := service.Get(ctx, "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+			errs <- err
+		}()
+	}
+	waitForStats(t, client, 2)
+	cancel()
+
+	assert.ErrorIs(t, <-errs, context.Canceled, "first waiter error incorrect")
+	assert.ErrorIs(t, <-errs, context.Canceled, "second waiter error incorrect")
+	assert.Eventually(t, func() bool { return httpClient.canceled.Load() == 1 }, time.Second, time.Millisecond,
+		"shared request should be canceled")
+}
+
+func TestRestClient_coalescingDistinctRequests(t *testing.T) {
+	httpClient := newBlockingHttpClient()
+	close(httpClient.release)
+	client, _ := NewRestClient(httpClient, NewRestClientParams{BaseUrl: baseFakeUrl, CoalesceGets: true})
+	service := NewAccountsService(client)
+
+	_, _, errFirst := service.Get(context.Background(), "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+	_, _, errSecond := service.Get(context.Background(), "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", WithHeader("X-Request-Id", "1"))
+
+	assert.Nil(t, errFirst, "first Get error should be nil")
+	assert.Nil(t, errSecond, "second Get error should be nil")
+	assert.Equal(t, int64(2), httpClient.requests.Load(), "requests sent incorrect")
+	assert.Equal(t, CoalescingStats{Requests: 2}, client.CoalescingStats(), "stats incorrect")
+}
+
+func TestRestClient_coalescingDisabled(t *testing.T) {
+	httpClient := newBlockingHttpClient()
+	close(httpClient.release)
+	client, _ := NewRestClient(httpClient, NewRestClientParams{BaseUrl: baseFakeUrl})
+
+	_, _, err := NewAccountsService(client).Get(context.Background(), "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+
+	assert.Nil(t, err, "Get error should be nil")
+	assert.Equal(t, CoalescingStats{}, client.CoalescingStats(), "stats incorrect")
+}
@@ -0,0 +1,364 @@
+package form3
+
+import (
+	"bytes"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"log"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Environment variables read by ClientConfig.ApplyEnv and NewRestClientFromEnv.
+// API_URL is the variable already used by the docker compose stack.
+const (
+	EnvConfigFile     = "FORM3_CONFIG"
+	EnvProfile        = "FORM3_PROFILE"
+	EnvBaseURL        = "API_URL"
+	EnvTimeout        = "FORM3_TIMEOUT"
+	EnvRetryAttempts  = "FORM3_RETRY_MAX_ATTEMPTS"
+	EnvRateLimit      = "FORM3_RATE_LIMIT"
+	EnvAuthToken      = "FORM3_AUTH_TOKEN"
+	EnvLoggingEnabled = "FORM3_LOGGING"
+)
+
+const (
+	defaultLogPrefix   = "form3: "
+	configProfilesPath = "profiles"
+)
+
+// ClientConfig holds the settings of a RestClient as written in a configuration file.
+// Durations use the time.ParseDuration format, e.g. 10s or 500ms.
+type ClientConfig struct {
+	BaseURL         string           `yaml:"base_url" json:"base_url"`
+	Timeout         string           `yaml:"timeout" json:"timeout"`
+	MaxResponseSize int64            `yaml:"max_response_size" json:"max_response_size"`
+	Retry           *RetryConfig     `yaml:"retry" json:"retry"`
+	RateLimit       *RateLimitConfig `yaml:"rate_limit" json:"rate_limit"`
+	Auth            *AuthConfig      `yaml:"auth" json:"auth"`
+	Logging         *LoggingConfig   `yaml:"logging" json:"logging"`
+	TLS             *TLSConfig       `yaml:"tls" json:"tls"`
+
+	// profilePath, profileKeys and envKeys locate the origin of the settings for validation errors
+	profilePath string
+	profileKeys map[string]bool
+	envKeys     []string
+}
+
+type RetryConfig struct {
+	MaxAttempts int    `yaml:"max_attempts" json:"max_attempts"`
+	Backoff     string `yaml:"backoff" json:"backoff"`
+	MaxBackoff  string `yaml:"max_backoff" json:"max_backoff"`
+}
+
+type RateLimitConfig struct {
+	RequestsPerSecond float64 `yaml:"requests_per_second" json:"requests_per_second"`
+	Burst             int     `yaml:"burst" json:"burst"`
+}
+
+type AuthConfig struct {
+	Token string `yaml:"token" json:"token"`
+}
+
+type LoggingConfig struct {
+	Enabled bool   `yaml:"enabled" json:"enabled"`
+	Prefix  string `yaml:"prefix" json:"prefix"`
+}
+
+type TLSConfig struct {
+	ClientCertFile string   `yaml:"client_cert_file" json:"client_cert_file"`
+	ClientKeyFile  string   `yaml:"client_key_file" json:"client_key_file"`
+	RootCAFiles    []string `yaml:"root_ca_files" json:"root_ca_files"`
+}
+
+// ConfigError is a configuration validation error, Key points to the offending setting
+// as a path in the file, e.g. profiles.staging.retry.backoff, or as an environment variable
+type ConfigError struct {
+	Key     string
+	Message string
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("invalid config %s: %s", e.Key, e.Message)
+}
+
+// configFile is the format of a configuration file. The top level settings apply to every
+// profile, and each profile overrides them:
+//
+//	base_url: http://localhost:8080/v1
+//	timeout: 10s
+//	default_profile: local
+//	profiles:
+//	  local: {}
+//	  staging:
+//	    base_url: https://staging.example.com/v1
+//	    retry: {max_attempts: 3, backoff: 100ms}
+//
+// T holds the settings of the application reading the file with LoadConfigWith, at the same levels.
+type configFile[T any] struct {
+	configSettings[T] `yaml:",inline"`
+	DefaultProfile    string               `yaml:"default_profile"`
+	Profiles          map[string]yaml.Node `yaml:"profiles"`
+}
+
+type configSettings[T any] struct {
+	ClientConfig `yaml:",inline"`
+	Extra        T `yaml:",inline"`
+}
+
+// strictConfigFile is configFile with decoded profiles, to reject the unknown keys of every level
+type strictConfigFile[T any] struct {
+	configSettings[T] `yaml:",inline"`
+	DefaultProfile    string                       `yaml:"default_profile"`
+	Profiles          map[string]configSettings[T] `yaml:"profiles"`
+}
+
+// LoadConfig reads a YAML or JSON configuration file and returns the settings of profile.
+// The default_profile of the file is used when profile is empty, and the top level settings when there is none.
+// Unknown keys are rejected.
+func LoadConfig(path string, profile string) (*ClientConfig, error) {
+	config, _, err := LoadConfigWith[struct{}](path, profile)
+	return config, err
+}
+
+// LoadConfigWith is LoadConfig for applications keeping their own settings in the same file, e.g. form3ctl.
+// The fields of T, a struct with yaml tags, are read next to the client settings, at the top level and
+// in the profiles.
+func LoadConfigWith[T any](path string, profile string) (*ClientConfig, *T, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// JSON documents are valid YAML, so both formats are read the same way
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err = decoder.Decode(&strictConfigFile[T]{}); err != nil {
+		return nil, nil, fmt.Errorf("reading config %s: %w", path, err)
+	}
+
+	// the profiles are kept as nodes so they override only the settings they set
+	file := &configFile[T]{}
+	if err = yaml.Unmarshal(data, file); err != nil {
+		return nil, nil, fmt.Errorf("reading config %s: %w", path, err)
+	}
+
+	settings := file.configSettings
+	if profile == "" {
+		profile = file.DefaultProfile
+	}
+	if profile == "" {
+		return &settings.ClientConfig, &settings.Extra, nil
+	}
+
+	node, ok := file.Profiles[profile]
+	if !ok {
+		return nil, nil, &ConfigError{Key: configProfilesPath + "." + profile, Message: "profile not found"}
+	}
+	if err = node.Decode(&settings); err != nil {
+		return nil, nil, &ConfigError{Key: configProfilesPath + "." + profile, Message: err.Error()}
+	}
+	settings.profilePath = configProfilesPath + "." + profile
+	settings.profileKeys = map[string]bool{}
+	collectKeys(&node, "", settings.profileKeys)
+
+	return &settings.ClientConfig, &settings.Extra, nil
+}
+
+// collectKeys adds the paths of the values set in a mapping node to keys, e.g. retry.backoff
+func collectKeys(node *yaml.Node, prefix string, keys map[string]bool) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		path := prefix + node.Content[i].Value
+		if value := node.Content[i+1]; value.Kind == yaml.MappingNode {
+			collectKeys(value, path+".", keys)
+			continue
+		}
+		keys[path] = true
+	}
+}
+
+// ApplyEnv overrides the settings with the environment variables that are set
+func (c *ClientConfig) ApplyEnv(getenv func(string) string) error {
+	if value := getenv(EnvBaseURL); value != "" {
+		c.BaseURL = value
+		c.envKeys = append(c.envKeys, EnvBaseURL)
+	}
+	if value := getenv(EnvTimeout); value != "" {
+		c.Timeout = value
+		c.envKeys = append(c.envKeys, EnvTimeout)
+	}
+	if value := getenv(EnvRetryAttempts); value != "" {
+		attempts, err := strconv.Atoi(value)
+		if err != nil {
+			return &ConfigError{Key: EnvRetryAttempts, Message: fmt.Sprintf("%q is not a number", value)}
+		}
+		if c.Retry == nil {
+			c.Retry = &RetryConfig{}
+		}
+		c.Retry.MaxAttempts = attempts
+		c.envKeys = append(c.envKeys, EnvRetryAttempts)
+	}
+	if value := getenv(EnvRateLimit); value != "" {
+		rate, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return &ConfigError{Key: EnvRateLimit, Message: fmt.Sprintf("%q is not a number", value)}
+		}
+		if c.RateLimit == nil {
+			c.RateLimit = &RateLimitConfig{}
+		}
+		c.RateLimit.RequestsPerSecond = rate
+		c.envKeys = append(c.envKeys, EnvRateLimit)
+	}
+	if value := getenv(EnvAuthToken); value != "" {
+		c.Auth = &AuthConfig{Token: value}
+	}
+	if value := getenv(EnvLoggingEnabled); value != "" {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return &ConfigError{Key: EnvLoggingEnabled, Message: fmt.Sprintf("%q is not a boolean", value)}
+		}
+		if c.Logging == nil {
+			c.Logging = &LoggingConfig{}
+		}
+		c.Logging.Enabled = enabled
+	}
+
+	return nil
+}
+
+// Params validates the settings and returns them as NewRestClientParams
+func (c *ClientConfig) Params() (NewRestClientParams, error) {
+	params := NewRestClientParams{
+		BaseUrl:         c.BaseURL,
+		MaxResponseSize: c.MaxResponseSize,
+	}
+
+	if c.BaseURL == "" {
+		return params, c.error("base_url", EnvBaseURL, "is required, set it in the config file or with "+EnvBaseURL)
+	}
+	if baseURL, err := url.ParseRequestURI(c.BaseURL); err != nil || baseURL.Scheme == "" || baseURL.Host == "" {
+		return params, c.error("base_url", EnvBaseURL, fmt.Sprintf("%q is not an absolute URL", c.BaseURL))
+	}
+	if c.MaxResponseSize < 0 {
+		return params, c.error("max_response_size", "", "must not be negative")
+	}
+
+	var err error
+	if params.Timeout, err = c.duration("timeout", EnvTimeout, c.Timeout); err != nil {
+		return params, err
+	}
+
+	if c.Retry != nil {
+		if c.Retry.MaxAttempts < 1 {
+			return params, c.error("retry.max_attempts", EnvRetryAttempts, "must be greater than 0")
+		}
+		policy := &RetryPolicy{MaxAttempts: c.Retry.MaxAttempts}
+		if policy.Backoff, err = c.duration("retry.backoff", "", c.Retry.Backoff); err != nil {
+			return params, err
+		}
+		if policy.MaxBackoff, err = c.duration("retry.max_backoff", "", c.Retry.MaxBackoff); err != nil {
+			return params, err
+		}
+		params.RetryPolicy = policy
+	}
+
+	if c.RateLimit != nil {
+		if c.RateLimit.RequestsPerSecond <= 0 {
+			return params, c.error("rate_limit.requests_per_second", EnvRateLimit, "must be greater than 0")
+		}
+		if c.RateLimit.Burst < 0 {
+			return params, c.error("rate_limit.burst", "", "must not be negative")
+		}
+		params.RateLimit = &RateLimit{RequestsPerSecond: c.RateLimit.RequestsPerSecond, Burst: c.RateLimit.Burst}
+	}
+
+	if c.Auth != nil {
+		params.AuthToken = c.Auth.Token
+	}
+
+	if c.Logging != nil && c.Logging.Enabled {
+		prefix := c.Logging.Prefix
+		if prefix == "" {
+			prefix = defaultLogPrefix
+		}
+		params.Logger = log.New(os.Stderr, prefix, log.LstdFlags)
+	}
+
+	if c.TLS != nil {
+		if (c.TLS.ClientCertFile == "") != (c.TLS.ClientKeyFile == "") {
+			return params, c.error("tls.client_key_file", "", "client_cert_file and client_key_file must be set together")
+		}
+		params.Transport = &TransportConfig{
+			ClientCertFile: c.TLS.ClientCertFile,
+			ClientKeyFile:  c.TLS.ClientKeyFile,
+			RootCAFiles:    c.TLS.RootCAFiles,
+		}
+	}
+
+	return params, nil
+}
+
+func (c *ClientConfig) duration(key string, envKey string, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, c.error(key, envKey, fmt.Sprintf("%q is not a duration", value))
+	}
+	if duration < 0 {
+		return 0, c.error(key, envKey, "must not be negative")
+	}
+
+	return duration, nil
+}
+
+// error returns a ConfigError pointing to the environment variable if it set the value, or to the file key,
+// in the profile if it set the value and at the top level otherwise
+func (c *ClientConfig) error(key string, envKey string, message string) error {
+	for _, applied := range c.envKeys {
+		if envKey != "" && applied == envKey {
+			return &ConfigError{Key: envKey, Message: message}
+		}
+	}
+	if c.profileKeys[key] {
+		return &ConfigError{Key: c.profilePath + "." + key, Message: message}
+	}
+
+	return &ConfigError{Key: key, Message: message}
+}
+
+// NewRestClientFromEnv returns a RestClient configured from the file in FORM3_CONFIG, if set,
+// using the profile in FORM3_PROFILE, and overridden by the environment variables, e.g. API_URL.
+// FORM3_PROFILE without FORM3_CONFIG is a ConfigError.
+func NewRestClientFromEnv() (*RestClient, error) {
+	config := &ClientConfig{}
+	path, profile := os.Getenv(EnvConfigFile), os.Getenv(EnvProfile)
+	switch {
+	case path != "":
+		var err error
+		if config, err = LoadConfig(path, profile); err != nil {
+			return nil, err
+		}
+	case profile != "":
+		return nil, &ConfigError{Key: EnvProfile, Message: "is set without " + EnvConfigFile + ", there is no profile to read"}
+	}
+
+	if err := config.ApplyEnv(os.Getenv); err != nil {
+		return nil, err
+	}
+
+	params, err := config.Params()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRestClient(nil, params)
+}
@@ -0,0 +1,213 @@
+package form3
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testConfigFile = `
+base_url: http://localhost:8080/v1
+timeout: 10s
+default_profile: local
+profiles:
+  local: {}
+  staging:
+    base_url: https://staging.example.com/v1
+    retry:
+      max_attempts: 3
+      backoff: 100ms
+      max_backoff: 2s
+    rate_limit:
+      requests_per_second: 20
+      burst: 5
+    auth:
+      token: secret
+  broken:
+    retry:
+      max_attempts: 3
+      backoff: soon
+`
+
+func writeTestConfig(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Error writing config file: %v", err)
+	}
+
+	return path
+}
+
+func TestLoadConfig_profiles(t *testing.T) {
+	path := writeTestConfig(t, "form3.yaml", testConfigFile)
+
+	config, err := LoadConfig(path, "")
+	assert.Nil(t, err, "Error should be nil")
+	params, err := config.Params()
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, "http://localhost:8080/v1", params.BaseUrl, "Default profile BaseUrl incorrect")
+	assert.Equal(t, 10*time.Second, params.Timeout, "Default profile Timeout incorrect")
+	assert.Nil(t, params.RetryPolicy, "Default profile RetryPolicy should be nil")
+
+	config, err = LoadConfig(path, "staging")
+	assert.Nil(t, err, "Error should be nil")
+	params, err = config.Params()
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, "https://staging.example.com/v1", params.BaseUrl, "Staging BaseUrl incorrect")
+	assert.Equal(t, 10*time.Second, params.Timeout, "Staging should inherit the top level Timeout")
+	assert.Equal(t, &RetryPolicy{MaxAttempts: 3, Backoff: 100 * time.Millisecond, MaxBackoff: 2 * time.Second}, params.RetryPolicy, "Staging RetryPolicy incorrect")
+	assert.Equal(t, &RateLimit{RequestsPerSecond: 20, Burst: 5}, params.RateLimit, "Staging RateLimit incorrect")
+	assert.Equal(t, "secret", params.AuthToken, "Staging AuthToken incorrect")
+}
+
+func TestLoadConfig_json(t *testing.T) {
+	path := writeTestConfig(t, "form3.json", `{"base_url":"http://localhost:8080/v1","logging":{"enabled":true}}`)
+
+	config, err := LoadConfig(path, "")
+	assert.Nil(t, err, "Error should be nil")
+	params, err := config.Params()
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, "http://localhost:8080/v1", params.BaseUrl, "BaseUrl incorrect")
+	assert.NotNil(t, params.Logger, "Logger should be set")
+}
+
+func TestLoadConfig_validationErrors(t *testing.T) {
+	path := writeTestConfig(t, "form3.yaml", testConfigFile)
+
+	config, _ := LoadConfig(path, "broken")
+	_, err := config.Params()
+
+	var configErr *ConfigError
+	assert.True(t, errors.As(err, &configErr), "Error should be a ConfigError")
+	assert.Equal(t, "profiles.broken.retry.backoff", configErr.Key, "Error key incorrect")
+	assert.Equal(t, `invalid config profiles.broken.retry.backoff: "soon" is not a duration`, err.Error(), "Error message expected")
+
+	path = writeTestConfig(t, "inherited.yaml", "base_url: http://localhost:8080/v1\ntimeout: soon\nprofiles:\n  staging:\n    retry:\n      max_attempts: 0\n")
+	config, _ = LoadConfig(path, "staging")
+	_, err = config.Params()
+	assert.Equal(t, `invalid config timeout: "soon" is not a duration`, err.Error(), "inherited settings should be reported at the top level")
+	config.Timeout = ""
+	_, err = config.Params()
+	assert.Equal(t, "invalid config profiles.staging.retry.max_attempts: must be greater than 0", err.Error(), "Error message expected")
+
+	_, err = LoadConfig(path, "production")
+	assert.Equal(t, "invalid config profiles.production: profile not found", err.Error(), "Error message expected")
+
+	_, err = (&ClientConfig{}).Params()
+	assert.Equal(t, "invalid config base_url: is required, set it in the config file or with API_URL", err.Error(), "Error message expected")
+}
+
+func TestLoadConfig_unknownKeys(t *testing.T) {
+	path := writeTestConfig(t, "form3.yaml", "base_url: http://localhost:8080/v1\nprofiles:\n  staging:\n    retyr:\n      max_attempts: 3\n")
+
+	_, err := LoadConfig(path, "")
+
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Contains(t, err.Error(), "line 4: field retyr not found", "Error message expected")
+}
+
+func TestLoadConfigWith(t *testing.T) {
+	path := writeTestConfig(t, "form3.yaml", "base_url: http://localhost:8080/v1\noutput: table\nprofiles:\n  staging:\n    output: json\n")
+	type settings struct {
+		Output string `yaml:"output"`
+	}
+
+	config, extra, err := LoadConfigWith[settings](path, "")
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, "table", extra.Output, "Top level Output incorrect")
+
+	config, extra, err = LoadConfigWith[settings](path, "staging")
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, "json", extra.Output, "Staging Output incorrect")
+	assert.Equal(t, "http://localhost:8080/v1", config.BaseURL, "Staging should inherit the top level BaseURL")
+
+	_, err = LoadConfig(path, "")
+	assert.NotNil(t, err, "Unknown keys of another application should be rejected")
+}
+
+func TestClientConfig_Params_invalidBaseURL(t *testing.T) {
+	path := writeTestConfig(t, "form3.yaml", "profiles:\n  local:\n    base_url: localhost:8080\n")
+
+	config, err := LoadConfig(path, "local")
+	assert.Nil(t, err, "Error should be nil")
+	_, err = config.Params()
+
+	var configErr *ConfigError
+	assert.True(t, errors.As(err, &configErr), "Error should be a ConfigError")
+	assert.Equal(t, "profiles.local.base_url", configErr.Key, "Error key incorrect")
+
+	err = config.ApplyEnv(func(key string) string {
+		return map[string]string{EnvBaseURL: "http//account_api"}[key]
+	})
+	assert.Nil(t, err, "Error should be nil")
+	_, err = config.Params()
+	assert.Equal(t, `invalid config API_URL: "http//account_api" is not an absolute URL`, err.Error(), "Error message expected")
+}
+
+func TestClientConfig_ApplyEnv(t *testing.T) {
+	env := map[string]string{
+		"API_URL":                  "http://account_api:8080/v1",
+		"FORM3_TIMEOUT":            "5s",
+		"FORM3_RETRY_MAX_ATTEMPTS": "2",
+		"FORM3_AUTH_TOKEN":         "from-env",
+	}
+	config := &ClientConfig{BaseURL: "http://localhost:8080/v1", Timeout: "10s"}
+
+	err := config.ApplyEnv(func(key string) string { return env[key] })
+	assert.Nil(t, err, "Error should be nil")
+	params, err := config.Params()
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, "http://account_api:8080/v1", params.BaseUrl, "API_URL should override base_url")
+	assert.Equal(t, 5*time.Second, params.Timeout, "FORM3_TIMEOUT should override timeout")
+	assert.Equal(t, 2, params.RetryPolicy.MaxAttempts, "FORM3_RETRY_MAX_ATTEMPTS should set the retries")
+	assert.Equal(t, "from-env", params.AuthToken, "FORM3_AUTH_TOKEN should set the token")
+}
+
+func TestClientConfig_ApplyEnv_validationErrors(t *testing.T) {
+	config := &ClientConfig{BaseURL: "http://localhost:8080/v1"}
+	err := config.ApplyEnv(func(key string) string {
+		return map[string]string{"FORM3_TIMEOUT": "-1s"}[key]
+	})
+	assert.Nil(t, err, "Error should be nil")
+
+	_, err = config.Params()
+	assert.Equal(t, "invalid config FORM3_TIMEOUT: must not be negative", err.Error(), "Error should point to the env variable")
+
+	err = config.ApplyEnv(func(key string) string {
+		return map[string]string{"FORM3_LOGGING": "maybe"}[key]
+	})
+	assert.Equal(t, `invalid config FORM3_LOGGING: "maybe" is not a boolean`, err.Error(), "Error message expected")
+}
+
+func TestNewRestClientFromEnv(t *testing.T) {
+	path := writeTestConfig(t, "form3.yaml", testConfigFile)
+	t.Setenv("FORM3_CONFIG", path)
+	t.Setenv("FORM3_PROFILE", "staging")
+	t.Setenv("API_URL", "")
+
+	client, err := NewRestClientFromEnv()
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, "https://staging.example.com/v1", client.baseURL.String(), "Base URL incorrect")
+	assert.Equal(t, 3, client.retryPolicy.MaxAttempts, "Retry policy incorrect")
+
+	t.Setenv("FORM3_CONFIG", "")
+	t.Setenv("API_URL", "http://account_api:8080/v1")
+
+	_, err = NewRestClientFromEnv()
+
+	assert.Equal(t, "invalid config FORM3_PROFILE: is set without FORM3_CONFIG, there is no profile to read", err.Error(),
+		"Error message expected")
+
+	t.Setenv("FORM3_PROFILE", "")
+
+	client, err = NewRestClientFromEnv()
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, "http://account_api:8080/v1", client.baseURL.String(), "Base URL incorrect")
+}
@@ -0,0 +1,434 @@
+// Package conformance checks that an implementation of the account API behaves like the Form3 one.
+// It runs the scenarios the integration tests run against any base URL or RestClient and reports
+// which behaviours are supported.
+package conformance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"form3-interview-accountapi/form3"
+	"github.com/google/uuid"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const (
+	ScenarioCreate          = "create"
+	ScenarioGet             = "get"
+	ScenarioList            = "list"
+	ScenarioDelete          = "delete"
+	ScenarioVersionConflict = "version_conflict"
+	ScenarioErrorMessages   = "error_messages"
+)
+
+// Scenarios are the names of all the scenarios, in the order they run
+var Scenarios = []string{
+	ScenarioCreate,
+	ScenarioGet,
+	ScenarioList,
+	ScenarioDelete,
+	ScenarioVersionConflict,
+	ScenarioErrorMessages,
+}
+
+type Config struct {
+	// BaseURL is the URL of the API, e.g. http://localhost:8080/v1. Ignored when Client is set
+	BaseURL string
+	Client  *form3.RestClient
+	// Scenarios are the scenarios run, all of them by default
+	Scenarios []string
+	// NewAccount returns the accounts created by the scenarios, the package NewAccount by default
+	NewAccount func() *form3.Account
+}
+
+// Result is the outcome of a scenario
+type Result struct {
+	Scenario  string
+	Supported bool
+	// Err is the behaviour that did not conform, nil when supported
+	Err error
+}
+
+// Report lists the results of the scenarios run
+type Report struct {
+	Results []Result
+	// CleanupErrors are the errors deleting the accounts created by the scenarios
+	CleanupErrors []error
+}
+
+// Supported reports whether the scenario passed
+func (r *Report) Supported(scenario string) bool {
+	for _, result := range r.Results {
+		if result.Scenario == scenario {
+			return result.Supported
+		}
+	}
+
+	return false
+}
+
+// Conforms reports whether every scenario run passed
+func (r *Report) Conforms() bool {
+	for _, result := range r.Results {
+		if !result.Supported {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r *Report) String() string {
+	var report strings.Builder
+	for _, result := range r.Results {
+		if result.Supported {
+			fmt.Fprintf(&report, "%-16s supported\n", result.Scenario)
+			continue
+		}
+		fmt.Fprintf(&report, "%-16s not supported: %v\n", result.Scenario, result.Err)
+	}
+
+	return report.String()
+}
+
+// Run runs the scenarios of the config against the API, deleting the accounts they create.
+// An error is returned only when the client cannot be built or a scenario is unknown.
+func Run(ctx context.Context, config Config) (*Report, error) {
+	s, err := NewSuite(config)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{}
+	for _, name := range s.Scenarios() {
+		err := s.Run(ctx, name)
+		report.Results = append(report.Results, Result{Scenario: name, Supported: err == nil, Err: err})
+	}
+	report.CleanupErrors = s.Cleanup(ctx)
+
+	return report, nil
+}
+
+// Suite runs the scenarios of a config one by one, see the conformancetest package to run them as subtests
+type Suite struct {
+	service    *form3.AccountsService
+	newAccount func() *form3.Account
+	scenarios  []string
+	created    []string
+}
+
+// NewSuite returns a Suite instance, an error when the client cannot be built or a scenario is unknown
+func NewSuite(config Config) (*Suite, error) {
+	client := config.Client
+	if client == nil {
+		var err error
+		client, err = form3.NewRestClient(nil, form3.NewRestClientParams{BaseUrl: config.BaseURL})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	names := config.Scenarios
+	if len(names) == 0 {
+		names = Scenarios
+	}
+	for _, name := range names {
+		if _, ok := scenarios[name]; !ok {
+			return nil, fmt.Errorf("unknown scenario %s", name)
+		}
+	}
+
+	newAccount := config.NewAccount
+	if newAccount == nil {
+		newAccount = NewAccount
+	}
+
+	return &Suite{service: form3.NewAccountsService(client), newAccount: newAccount, scenarios: names}, nil
+}
+
+// Scenarios returns the names of the scenarios of the config, in the order they run
+func (s *Suite) Scenarios() []string {
+	return append([]string(nil), s.scenarios...)
+}
+
+// Run runs the scenario, returning the behaviour that did not conform, nil when supported
+func (s *Suite) Run(ctx context.Context, scenario string) error {
+	run, ok := scenarios[scenario]
+	if !ok {
+		return fmt.Errorf("unknown scenario %s", scenario)
+	}
+
+	return run(ctx, s)
+}
+
+// NewAccount returns a Belgian personal account with new IDs, the account created by the scenarios by default
+func NewAccount() *form3.Account {
+	return &form3.Account{
+		ID:             uuid.New().String(),
+		OrganisationID: uuid.New().String(),
+		Type:           form3.AcctTypeAccounts,
+		Attributes: &form3.AccountAttributes{
+			AccountClassification: form3.AcctClassificationPersonal,
+			AlternativeNames:      []string{"foo", "bar"},
+			BankID:                "ZXE",
+			BankIDCode:            form3.BankIDCodeBelgium,
+			BaseCurrency:          form3.BaseCurrencyEur,
+			Country:               form3.CountryCodeBelgium,
+			Name:                  []string{"cristian", "pelegrin"},
+			Status:                form3.AcctStatusPending,
+		},
+	}
+}
+
+// create creates a new account, to be deleted by cleanup
+func (s *Suite) create(ctx context.Context) (*form3.Account, *form3.RestClientResponse, error) {
+	account, resp, err := s.service.Create(ctx, s.newAccount())
+	if err == nil && account != nil {
+		s.created = append(s.created, account.ID)
+	}
+
+	return account, resp, err
+}
+
+// Cleanup deletes the accounts created by the scenarios run, returning the errors deleting them
+func (s *Suite) Cleanup(ctx context.Context) []error {
+	var errs []error
+	for _, id := range s.created {
+		if _, err := s.service.DeleteLatest(ctx, id, form3.WithNotFoundAsSuccess()); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", id, err))
+		}
+	}
+	s.created = nil
+
+	return errs
+}
+
+var scenarios = map[string]func(ctx context.Context, s *Suite) error{
+	ScenarioCreate:          createScenario,
+	ScenarioGet:             getScenario,
+	ScenarioList:            listScenario,
+	ScenarioDelete:          deleteScenario,
+	ScenarioVersionConflict: versionConflictScenario,
+	ScenarioErrorMessages:   errorMessagesScenario,
+}
+
+// echoed returns the attributes of the account the API returns as they are sent
+func echoed(attributes *form3.AccountAttributes) form3.AccountAttributes {
+	if attributes == nil {
+		return form3.AccountAttributes{}
+	}
+
+	return form3.AccountAttributes{
+		AccountClassification:   attributes.AccountClassification,
+		AccountMatchingOptOut:   attributes.AccountMatchingOptOut,
+		AccountNumber:           attributes.AccountNumber,
+		AlternativeNames:        attributes.AlternativeNames,
+		BankID:                  attributes.BankID,
+		BankIDCode:              attributes.BankIDCode,
+		BaseCurrency:            attributes.BaseCurrency,
+		Bic:                     attributes.Bic,
+		Country:                 attributes.Country,
+		Iban:                    attributes.Iban,
+		JointAccount:            attributes.JointAccount,
+		Name:                    attributes.Name,
+		SecondaryIdentification: attributes.SecondaryIdentification,
+		Status:                  attributes.Status,
+		Switched:                attributes.Switched,
+	}
+}
+
+func sameTime(a, b *time.Time) bool {
+	return a == nil && b == nil || a != nil && b != nil && a.Equal(*b)
+}
+
+// createScenario checks that a created account is returned with its values, version 0 and its timestamps
+func createScenario(ctx context.Context, s *Suite) error {
+	account := s.newAccount()
+	created, resp, err := s.service.Create(ctx, account)
+	if err != nil {
+		return fmt.Errorf("create: %w", err)
+	}
+	s.created = append(s.created, created.ID)
+
+	switch {
+	case resp.StatusCode != http.StatusCreated:
+		return fmt.Errorf("create: status %d, expected %d", resp.StatusCode, http.StatusCreated)
+	case created.ID != account.ID || created.OrganisationID != account.OrganisationID:
+		return errors.New("create: the account returned is not the one sent")
+	case !reflect.DeepEqual(echoed(created.Attributes), echoed(account.Attributes)):
+		return errors.New("create: the attributes returned are not the ones sent")
+	case created.Version != 0:
+		return fmt.Errorf("create: version %d, expected 0", created.Version)
+	case created.CreatedOn == nil || created.ModifiedOn == nil:
+		return errors.New("create: created_on and modified_on are not set")
+	}
+
+	return nil
+}
+
+// getScenario checks that a created account can be fetched, and that a missing one is not found
+func getScenario(ctx context.Context, s *Suite) error {
+	created, _, err := s.create(ctx)
+	if err != nil {
+		return fmt.Errorf("create: %w", err)
+	}
+
+	fetched, resp, err := s.service.Get(ctx, created.ID)
+	if err != nil {
+		return fmt.Errorf("get: %w", err)
+	}
+	if err = expectStatus("get", resp, http.StatusOK); err != nil {
+		return err
+	}
+	if fetched.ID != created.ID {
+		return fmt.Errorf("get: id %s, expected %s", fetched.ID, created.ID)
+	}
+	if !sameTime(fetched.CreatedOn, created.CreatedOn) || !sameTime(fetched.ModifiedOn, created.ModifiedOn) {
+		return errors.New("get: created_on and modified_on are not the ones returned by create")
+	}
+
+	_, resp, _ = s.service.Get(ctx, uuid.New().String())
+
+	return expectStatus("get missing account", resp, http.StatusNotFound)
+}
+
+// listScenario checks that a created account is listed and that the page size is honoured
+func listScenario(ctx context.Context, s *Suite) error {
+	created, _, err := s.create(ctx)
+	if err != nil {
+		return fmt.Errorf("create: %w", err)
+	}
+	if _, _, err = s.create(ctx); err != nil {
+		return fmt.Errorf("create: %w", err)
+	}
+
+	page, resp, err := s.service.List(ctx, &form3.ListOptions{PageSize: 1})
+	if err != nil {
+		return fmt.Errorf("list: %w", err)
+	}
+	if err = expectStatus("list", resp, http.StatusOK); err != nil {
+		return err
+	}
+	if len(page) != 1 {
+		return fmt.Errorf("list: %d accounts in a page of size 1", len(page))
+	}
+
+	found := false
+	err = s.service.ListEach(ctx, nil, func(account *form3.Account) error {
+		if account.ID == created.ID {
+			found = true
+			return form3.ErrStopListing
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("list: %w", err)
+	}
+	if !found {
+		return fmt.Errorf("list: account %s is not listed", created.ID)
+	}
+
+	return nil
+}
+
+// deleteScenario checks that an account is deleted, and that deleting a missing one is not found
+func deleteScenario(ctx context.Context, s *Suite) error {
+	created, _, err := s.create(ctx)
+	if err != nil {
+		return fmt.Errorf("create: %w", err)
+	}
+
+	resp, err := s.service.Delete(ctx, created.ID, created.Version)
+	if err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+	if err = expectStatus("delete", resp, http.StatusNoContent); err != nil {
+		return err
+	}
+
+	_, resp, _ = s.service.Get(ctx, created.ID)
+	if err = expectStatus("get deleted account", resp, http.StatusNotFound); err != nil {
+		return err
+	}
+
+	resp, err = s.service.Delete(ctx, uuid.New().String(), 0)
+	if err != nil {
+		return fmt.Errorf("delete missing account: %w", err)
+	}
+
+	return expectStatus("delete missing account", resp, http.StatusNotFound)
+}
+
+// versionConflictScenario checks that deleting an account at another version conflicts and keeps it
+func versionConflictScenario(ctx context.Context, s *Suite) error {
+	created, _, err := s.create(ctx)
+	if err != nil {
+		return fmt.Errorf("create: %w", err)
+	}
+
+	resp, err := s.service.Delete(ctx, created.ID, created.Version+3)
+	if err == nil {
+		return errors.New("delete at another version: no error")
+	}
+	if err = expectStatus("delete at another version", resp, http.StatusConflict); err != nil {
+		return err
+	}
+
+	_, resp, _ = s.service.Get(ctx, created.ID)
+
+	return expectStatus("get account after conflict", resp, http.StatusOK)
+}
+
+// errorMessagesScenario checks the error messages of the Form3 API
+func errorMessagesScenario(ctx context.Context, s *Suite) error {
+	invalid := s.newAccount()
+	invalid.ID = "invalid-UUID"
+	_, resp, err := s.service.Create(ctx, invalid)
+	if err := expectError("create with invalid id", resp, err, http.StatusBadRequest, "id in body must be of type uuid"); err != nil {
+		return err
+	}
+
+	_, resp, err = s.service.Get(ctx, "invalid-UUID")
+	if err := expectError("get with invalid id", resp, err, http.StatusBadRequest, "id is not a valid uuid"); err != nil {
+		return err
+	}
+
+	missing := uuid.New().String()
+	_, resp, err = s.service.Get(ctx, missing)
+	if err := expectError("get missing account", resp, err, http.StatusNotFound, fmt.Sprintf("record %s does not exist", missing)); err != nil {
+		return err
+	}
+
+	created, _, err := s.create(ctx)
+	if err != nil {
+		return fmt.Errorf("create: %w", err)
+	}
+	resp, err = s.service.Delete(ctx, created.ID, created.Version+3)
+
+	return expectError("delete at another version", resp, err, http.StatusConflict, "invalid version")
+}
+
+func expectStatus(operation string, resp *form3.RestClientResponse, statusCode int) error {
+	if resp == nil || resp.Response == nil {
+		return fmt.Errorf("%s: no response, expected status %d", operation, statusCode)
+	}
+	if resp.StatusCode != statusCode {
+		return fmt.Errorf("%s: status %d, expected %d", operation, resp.StatusCode, statusCode)
+	}
+
+	return nil
+}
+
+func expectError(operation string, resp *form3.RestClientResponse, err error, statusCode int, message string) error {
+	if statusErr := expectStatus(operation, resp, statusCode); statusErr != nil {
+		return statusErr
+	}
+	if err == nil || !strings.Contains(err.Error(), message) {
+		return fmt.Errorf("%s: error %v, expected %q", operation, err, message)
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package conformance
+
+import (
+	"context"
+	"form3-interview-accountapi/form3"
+	"form3-interview-accountapi/form3/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRun_conforming(t *testing.T) {
+	api := testsupport.NewFakeAPI(t, testsupport.FakeAPIOptions{})
+
+	report, err := Run(context.Background(), Config{BaseURL: api.URL})
+
+	assert.Nil(t, err, "Run error should be nil")
+	assert.True(t, report.Conforms(), "API should conform:\n%s", report)
+	assert.Len(t, report.Results, len(Scenarios), "results incorrect")
+	assert.Empty(t, report.CleanupErrors, "CleanupErrors should be empty")
+	assert.Equal(t, 0, api.Len(), "created accounts should be deleted")
+}
+
+func TestRun_notConforming(t *testing.T) {
+	api := testsupport.NewFakeAPI(t, testsupport.FakeAPIOptions{IgnoreVersion: true, PlainErrors: true})
+
+	report, err := Run(context.Background(), Config{BaseURL: api.URL})
+
+	assert.Nil(t, err, "Run error should be nil")
+	assert.False(t, report.Conforms(), "API should not conform")
+	assert.True(t, report.Supported(ScenarioCreate), "create should be supported")
+	assert.True(t, report.Supported(ScenarioGet), "get should be supported")
+	assert.True(t, report.Supported(ScenarioList), "list should be supported")
+	assert.True(t, report.Supported(ScenarioDelete), "delete should be supported")
+	assert.False(t, report.Supported(ScenarioVersionConflict), "version_conflict should not be supported")
+	assert.False(t, report.Supported(ScenarioErrorMessages), "error_messages should not be supported")
+	assert.Equal(t, "create           supported\n"+
+		"get              supported\n"+
+		"list             supported\n"+
+		"delete           supported\n"+
+		"version_conflict not supported: delete at another version: no error\n"+
+		"error_messages   not supported: create with invalid id: error Bad Request, expected \"id in body must be of type uuid\"\n",
+		report.String(), "report incorrect")
+}
+
+func TestRun_scenariosAndClient(t *testing.T) {
+	api := testsupport.NewFakeAPI(t, testsupport.FakeAPIOptions{})
+	client, _ := form3.NewRestClient(nil, form3.NewRestClientParams{BaseUrl: api.URL})
+
+	report, err := Run(context.Background(), Config{Client: client, Scenarios: []string{ScenarioGet}})
+
+	assert.Nil(t, err, "Run error should be nil")
+	assert.Equal(t, []Result{{Scenario: ScenarioGet, Supported: true}}, report.Results, "results incorrect")
+
+	_, err = Run(context.Background(), Config{Client: client, Scenarios: []string{"update"}})
+
+	assert.EqualError(t, err, "unknown scenario update", "Run error incorrect")
+}
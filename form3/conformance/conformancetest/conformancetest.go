@@ -0,0 +1,33 @@
+// Package conformancetest runs the conformance scenarios as Go tests, apart from the conformance package
+// so that programs checking an API do not link the testing package.
+package conformancetest
+
+import (
+	"context"
+	"form3-interview-accountapi/form3/conformance"
+	"testing"
+)
+
+// RunT runs every scenario of the config as a subtest of t, failing the ones not supported
+func RunT(t *testing.T, config conformance.Config) {
+	t.Helper()
+
+	s, err := conformance.NewSuite(config)
+	if err != nil {
+		t.Fatalf("Error creating conformance suite: %v", err)
+	}
+	t.Cleanup(func() {
+		for _, err := range s.Cleanup(context.Background()) {
+			t.Errorf("Error deleting account: %v", err)
+		}
+	})
+
+	for _, name := range s.Scenarios() {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			if err := s.Run(context.Background(), name); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,11 @@
+package conformancetest
+
+import (
+	"form3-interview-accountapi/form3/conformance"
+	"form3-interview-accountapi/form3/internal/testsupport"
+	"testing"
+)
+
+func TestRunT(t *testing.T) {
+	RunT(t, conformance.Config{BaseURL: testsupport.NewFakeAPI(t, testsupport.FakeAPIOptions{}).URL})
+}
@@ -0,0 +1,151 @@
+package form3
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// DecodeMode sets how RestClient.Do decodes the data of a response into the target
+type DecodeMode int
+
+const (
+	// DecodeModeDefault ignores the members the target does not model
+	DecodeModeDefault DecodeMode = iota
+	// DecodeModePreserveUnknown keeps the members the target does not model in its Extra map,
+	// so they are sent back when the value is written again
+	DecodeModePreserveUnknown
+	// DecodeModeStrict fails when the data has members the target does not model,
+	// useful in tests to detect schema drift
+	DecodeModeStrict
+)
+
+const extraFieldName = "Extra"
+
+// UnmarshalPreservingUnknown decodes data into v like json.Unmarshal and stores the members not
+// modelled by v, or by the structs nested in it, in their Extra map[string]json.RawMessage field if they have one.
+func UnmarshalPreservingUnknown(data []byte, v any) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return err
+	}
+
+	return collectUnknown(data, reflect.ValueOf(v))
+}
+
+// UnmarshalStrict decodes data into v like json.Unmarshal but fails on members not modelled by v
+func UnmarshalStrict(data []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	return decoder.Decode(v)
+}
+
+func decodeData(data []byte, v any, mode DecodeMode) error {
+	switch mode {
+	case DecodeModePreserveUnknown:
+		return UnmarshalPreservingUnknown(data, v)
+	case DecodeModeStrict:
+		return UnmarshalStrict(data, v)
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+func collectUnknown(data []byte, v reflect.Value) error {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil // null or not an array, nothing to collect
+		}
+		for i := 0; i < len(items) && i < v.Len(); i++ {
+			if err := collectUnknown(items[i], v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		var members map[string]json.RawMessage
+		if err := json.Unmarshal(data, &members); err != nil {
+			return nil // null or not an object, nothing to collect
+		}
+
+		var extra reflect.Value
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if field.Name == extraFieldName && field.Type == reflect.TypeOf(map[string]json.RawMessage{}) {
+				extra = v.Field(i)
+				continue
+			}
+
+			name, _, skip := jsonFieldName(field)
+			if skip {
+				continue
+			}
+			for _, key := range memberKeys(members, name) {
+				member := members[key]
+				delete(members, key)
+				if err := collectUnknown(member, v.Field(i)); err != nil {
+					return err
+				}
+			}
+		}
+
+		if extra.IsValid() && extra.CanSet() && len(members) > 0 {
+			extra.Set(reflect.ValueOf(members))
+		}
+	}
+
+	return nil
+}
+
+// marshalWithExtra appends the extra members to the JSON object data.
+// Extra must only hold members not modelled by the struct, otherwise they are duplicated.
+func marshalWithExtra(data []byte, extra map[string]json.RawMessage) ([]byte, error) {
+	if len(extra) == 0 {
+		return data, nil
+	}
+
+	keys := make([]string, 0, len(extra))
+	for key := range extra {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	object := bytes.TrimSuffix(bytes.TrimSpace(data), []byte("}"))
+	needsComma := !bytes.HasSuffix(bytes.TrimSpace(object), []byte("{"))
+	buf := bytes.NewBuffer(object)
+	for _, key := range keys {
+		if needsComma {
+			buf.WriteByte(',')
+		}
+		needsComma = true
+		name, _ := json.Marshal(key)
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(extra[key])
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// memberKeys returns the keys of members json.Unmarshal decodes into the field name, which it matches case-insensitively
+func memberKeys(members map[string]json.RawMessage, name string) []string {
+	var keys []string
+	for key := range members {
+		if strings.EqualFold(key, name) {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
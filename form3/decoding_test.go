@@ -0,0 +1,92 @@
+package form3
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+const accountWithUnknownMembers = `{"id":"a1b2c3","organisation_id":"b","type":"accounts","version":0,"relationships":{"master_account":{"data":[]}},` +
+	`"attributes":{"country":"GB","new_attribute":{"a":1},"name":["cristian"]}}`
+
+func TestUnmarshalPreservingUnknown(t *testing.T) {
+	account := new(Account)
+
+	err := UnmarshalPreservingUnknown([]byte(accountWithUnknownMembers), account)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, map[string]json.RawMessage{"relationships": json.RawMessage(`{"master_account":{"data":[]}}`)}, account.Extra, "account.Extra incorrect")
+	assert.Equal(t, map[string]json.RawMessage{"new_attribute": json.RawMessage(`{"a":1}`)}, account.Attributes.Extra, "account.Attributes.Extra incorrect")
+	assert.Equal(t, CountryCode("GB"), account.Attributes.Country, "account.Attributes.Country incorrect")
+
+	data, err := json.Marshal(account)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.JSONEq(t, accountWithUnknownMembers, string(data), "Unknown members should be sent back")
+}
+
+func TestUnmarshalPreservingUnknown_slice(t *testing.T) {
+	var accounts []Account
+
+	err := UnmarshalPreservingUnknown([]byte(`[{"id":"a1","foo":"bar"},{"id":"a2"}]`), &accounts)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, map[string]json.RawMessage{"foo": json.RawMessage(`"bar"`)}, accounts[0].Extra, "accounts[0].Extra incorrect")
+	assert.Nil(t, accounts[1].Extra, "accounts[1].Extra should be nil")
+}
+
+func TestUnmarshalPreservingUnknown_caseInsensitive(t *testing.T) {
+	account := &Account{}
+
+	err := UnmarshalPreservingUnknown([]byte(`{"ID":"a1","Attributes":{"Bank_ID":"400300","foo":"bar"}}`), account)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, "a1", account.ID, "ID incorrect")
+	assert.Equal(t, "400300", account.Attributes.BankID, "BankID incorrect")
+	assert.Nil(t, account.Extra, "Extra should be nil, ID is decoded into the ID field")
+	assert.Equal(t, map[string]json.RawMessage{"foo": json.RawMessage(`"bar"`)}, account.Attributes.Extra, "Attributes.Extra incorrect")
+}
+
+func TestUnmarshalStrict(t *testing.T) {
+	account := new(Account)
+
+	err := UnmarshalStrict([]byte(accountWithUnknownMembers), account)
+
+	assert.NotNil(t, err, "Error should be not nil")
+	assert.Contains(t, err.Error(), `unknown field "relationships"`, "Error message expected")
+}
+
+func TestAccount_MarshalJSON_extraOnEmptyAttributes(t *testing.T) {
+	attributes := AccountAttributes{Extra: map[string]json.RawMessage{"b": json.RawMessage(`2`), "a": json.RawMessage(`1`)}}
+
+	data, err := json.Marshal(attributes)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, `{"a":1,"b":2}`, string(data), "Attributes JSON incorrect")
+}
+
+func TestRestClient_Do_decodeModes(t *testing.T) {
+	mockedHttpClient := mockedHttpClientHandler(func(req *http.Request) (*http.Response, error) {
+		body := `{"data":` + accountWithUnknownMembers + `,"links":{"self":"/v1/organisation/accounts/a1b2c3"}}`
+		return mockedResponse(http.StatusOK, body, nil), nil
+	})
+
+	client, _ := NewRestClient(mockedHttpClient, NewRestClientParams{BaseUrl: baseFakeUrl})
+	account, _, err := NewAccountsService(client).Get(context.Background(), "a1b2c3")
+	assert.Nil(t, err, "Error should be nil")
+	assert.Nil(t, account.Extra, "account.Extra should be nil by default")
+
+	client, _ = NewRestClient(mockedHttpClient, NewRestClientParams{BaseUrl: baseFakeUrl, DecodeMode: DecodeModePreserveUnknown})
+	account, _, err = NewAccountsService(client).Get(context.Background(), "a1b2c3")
+	assert.Nil(t, err, "Error should be nil")
+	assert.Contains(t, account.Extra, "relationships", "account.Extra should hold unknown members")
+	assert.Contains(t, account.Attributes.Extra, "new_attribute", "account.Attributes.Extra should hold unknown members")
+
+	client, _ = NewRestClient(mockedHttpClient, NewRestClientParams{BaseUrl: baseFakeUrl, DecodeMode: DecodeModeStrict})
+	account, resp, err := NewAccountsService(client).Get(context.Background(), "a1b2c3")
+	assert.Nil(t, account, "Account should be nil")
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "Response code incorrect")
+	assert.Contains(t, err.Error(), `unknown field "relationships"`, "Error message expected")
+}
@@ -0,0 +1,258 @@
+// Package flatfile exports accounts of the API to CSV or JSON Lines files and imports them back.
+package flatfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"form3-interview-accountapi/form3"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Format string
+
+const (
+	FormatCSV Format = "csv"
+	// FormatNDJSON writes one JSON object per line
+	FormatNDJSON Format = "ndjson"
+)
+
+// defaultSeparator joins the values of a multi-valued field in a single CSV cell
+const defaultSeparator = "|"
+
+// Column maps a field of Account to a column of the file
+type Column struct {
+	// Header is the name of the column in the file
+	Header string
+	// Field is the path of the field using the API names, e.g. attributes.bank_id.
+	// Multi-valued fields such as attributes.name are written in a single column joined by the separator,
+	// or one value per column with an index, e.g. attributes.name[0]
+	Field string
+}
+
+// DefaultColumns are the columns used when none are configured
+var DefaultColumns = []Column{
+	{Header: "id", Field: "id"},
+	{Header: "organisation_id", Field: "organisation_id"},
+	{Header: "version", Field: "version"},
+	{Header: "country", Field: "attributes.country"},
+	{Header: "bank_id", Field: "attributes.bank_id"},
+	{Header: "bank_id_code", Field: "attributes.bank_id_code"},
+	{Header: "bic", Field: "attributes.bic"},
+	{Header: "account_number", Field: "attributes.account_number"},
+	{Header: "iban", Field: "attributes.iban"},
+	{Header: "base_currency", Field: "attributes.base_currency"},
+	{Header: "account_classification", Field: "attributes.account_classification"},
+	{Header: "name", Field: "attributes.name"},
+	{Header: "alternative_names", Field: "attributes.alternative_names"},
+	{Header: "secondary_identification", Field: "attributes.secondary_identification"},
+	{Header: "status", Field: "attributes.status"},
+}
+
+// field is a column resolved against the Account type
+type field struct {
+	Column
+	path  []string
+	index int // -1 when the column holds the whole field
+	typ   reflect.Type
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func resolveColumns(columns []Column) ([]field, error) {
+	if len(columns) == 0 {
+		columns = DefaultColumns
+	}
+
+	fields := make([]field, 0, len(columns))
+	for _, column := range columns {
+		resolved, err := resolveColumn(column)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, resolved)
+	}
+
+	return fields, nil
+}
+
+func resolveColumn(column Column) (field, error) {
+	resolved := field{Column: column, index: -1}
+
+	name := column.Field
+	if open := strings.IndexByte(name, '['); open >= 0 && strings.HasSuffix(name, "]") {
+		index, err := strconv.Atoi(name[open+1 : len(name)-1])
+		if err != nil || index < 0 {
+			return resolved, fmt.Errorf("column %s: invalid index in %s", column.Header, column.Field)
+		}
+		resolved.index = index
+		name = name[:open]
+	}
+	resolved.path = strings.Split(name, ".")
+
+	typ := reflect.TypeOf(form3.Account{})
+	for _, member := range resolved.path {
+		for typ.Kind() == reflect.Pointer {
+			typ = typ.Elem()
+		}
+		if typ.Kind() != reflect.Struct || typ == timeType {
+			return resolved, fmt.Errorf("column %s: unknown field %s", column.Header, column.Field)
+		}
+		structField, ok := fieldByJSONName(typ, member)
+		if !ok {
+			return resolved, fmt.Errorf("column %s: unknown field %s", column.Header, column.Field)
+		}
+		typ = structField.Type
+	}
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	if resolved.index >= 0 && typ.Kind() != reflect.Slice {
+		return resolved, fmt.Errorf("column %s: field %s is not multi-valued", column.Header, column.Field)
+	}
+	if typ.Kind() == reflect.Struct && typ != timeType {
+		return resolved, fmt.Errorf("column %s: field %s is not a single value", column.Header, column.Field)
+	}
+	resolved.typ = typ
+
+	return resolved, nil
+}
+
+func fieldByJSONName(typ reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < typ.NumField(); i++ {
+		structField := typ.Field(i)
+		tag := strings.Split(structField.Tag.Get("json"), ",")[0]
+		if tag == name {
+			return structField, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+// accountMembers returns the JSON members of account
+func accountMembers(account *form3.Account) (map[string]any, error) {
+	data, err := json.Marshal(account)
+	if err != nil {
+		return nil, err
+	}
+
+	members := map[string]any{}
+	err = json.Unmarshal(data, &members)
+
+	return members, err
+}
+
+// value returns the value of the field in the JSON members of an account, nil when not set
+func (f field) value(members map[string]any) any {
+	var value any = members
+	for _, member := range f.path {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = object[member]
+	}
+
+	if f.index >= 0 {
+		values, _ := value.([]any)
+		if f.index >= len(values) {
+			return nil
+		}
+		return values[f.index]
+	}
+
+	return value
+}
+
+// cell formats the value of the field for a CSV cell
+func (f field) cell(members map[string]any, separator string) string {
+	switch value := f.value(members).(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case []any:
+		values := make([]string, len(value))
+		for i, v := range value {
+			values[i] = fmt.Sprint(v)
+		}
+		return strings.Join(values, separator)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// set stores the value read from a file in the JSON members of an account.
+// Text values are converted to the type of the field, empty text leaves the field unset.
+func (f field) set(members map[string]any, value any, separator string) error {
+	text, isText := value.(string)
+	if isText {
+		if text == "" {
+			return nil
+		}
+
+		var err error
+		value, err = f.parse(text, separator)
+		if err != nil {
+			return fmt.Errorf("column %s: %w", f.Header, err)
+		}
+	}
+	if value == nil {
+		return nil
+	}
+
+	object := members
+	for _, member := range f.path[:len(f.path)-1] {
+		child, ok := object[member].(map[string]any)
+		if !ok {
+			child = map[string]any{}
+			object[member] = child
+		}
+		object = child
+	}
+
+	last := f.path[len(f.path)-1]
+	if f.index < 0 {
+		object[last] = value
+		return nil
+	}
+
+	values, _ := object[last].([]any)
+	for len(values) <= f.index {
+		values = append(values, nil)
+	}
+	values[f.index] = value
+	object[last] = values
+
+	return nil
+}
+
+func (f field) parse(text string, separator string) (any, error) {
+	typ := f.typ
+	if typ.Kind() == reflect.Slice {
+		if f.index >= 0 {
+			typ = typ.Elem()
+		} else {
+			values := []any{}
+			for _, v := range strings.Split(text, separator) {
+				values = append(values, v)
+			}
+			return values, nil
+		}
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		return strconv.ParseBool(text)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.ParseInt(text, 10, 64)
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(text, 64)
+	default:
+		return text, nil
+	}
+}
@@ -0,0 +1,130 @@
+package flatfile
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"form3-interview-accountapi/form3"
+	"io"
+)
+
+type ExportOptions struct {
+	// Format of the file, CSV by default
+	Format Format
+	// Columns written, DefaultColumns by default for CSV.
+	// NDJSON writes the whole account unless columns are set, then an object keyed by their headers
+	Columns []Column
+	// Separator joins multi-valued fields in a CSV cell, | by default
+	Separator string
+	// Filter restricts the accounts exported, as in form3.ListOptions
+	Filter map[string]string
+	// PageSize is the number of accounts listed per request
+	PageSize    int
+	CallOptions []form3.CallOption
+}
+
+type Exporter struct {
+	service form3.AccountsAPI
+	opts    ExportOptions
+}
+
+// NewExporter returns an Exporter instance.
+func NewExporter(service form3.AccountsAPI, opts ExportOptions) *Exporter {
+	return &Exporter{service: service, opts: opts}
+}
+
+// Export lists the accounts and streams them to w as they are received, returning how many were written
+func (e *Exporter) Export(ctx context.Context, w io.Writer) (int, error) {
+	write, flush, err := e.writer(w)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	listOpts := &form3.ListOptions{Filter: e.opts.Filter, PageSize: e.opts.PageSize}
+	err = e.service.ListEach(ctx, listOpts, func(account *form3.Account) error {
+		if err := write(account); err != nil {
+			return fmt.Errorf("writing account %s: %w", account.ID, err)
+		}
+		count++
+		return nil
+	}, e.opts.CallOptions...)
+	if err != nil {
+		return count, err
+	}
+
+	return count, flush()
+}
+
+// writer returns the function writing an account in the configured format and the one flushing the output
+func (e *Exporter) writer(w io.Writer) (func(*form3.Account) error, func() error, error) {
+	separator := e.opts.Separator
+	if separator == "" {
+		separator = defaultSeparator
+	}
+
+	switch e.opts.Format {
+	case FormatCSV, "":
+		fields, err := resolveColumns(e.opts.Columns)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		csvWriter := csv.NewWriter(w)
+		header := make([]string, len(fields))
+		for i, f := range fields {
+			header[i] = f.Header
+		}
+		if err = csvWriter.Write(header); err != nil {
+			return nil, nil, err
+		}
+
+		write := func(account *form3.Account) error {
+			members, err := accountMembers(account)
+			if err != nil {
+				return err
+			}
+			record := make([]string, len(fields))
+			for i, f := range fields {
+				record[i] = f.cell(members, separator)
+			}
+			return csvWriter.Write(record)
+		}
+		flush := func() error {
+			csvWriter.Flush()
+			return csvWriter.Error()
+		}
+		return write, flush, nil
+	case FormatNDJSON:
+		encoder := json.NewEncoder(w)
+		noFlush := func() error { return nil }
+		if len(e.opts.Columns) == 0 {
+			write := func(account *form3.Account) error {
+				return encoder.Encode(account)
+			}
+			return write, noFlush, nil
+		}
+
+		fields, err := resolveColumns(e.opts.Columns)
+		if err != nil {
+			return nil, nil, err
+		}
+		write := func(account *form3.Account) error {
+			members, err := accountMembers(account)
+			if err != nil {
+				return err
+			}
+			object := make(map[string]any, len(fields))
+			for _, f := range fields {
+				if value := f.value(members); value != nil {
+					object[f.Header] = value
+				}
+			}
+			return encoder.Encode(object)
+		}
+		return write, noFlush, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown format %s", e.opts.Format)
+	}
+}
@@ -0,0 +1,117 @@
+package flatfile
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"form3-interview-accountapi/form3"
+	"form3-interview-accountapi/form3/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"strings"
+	"testing"
+)
+
+func newFakeAPI(t *testing.T, accounts ...form3.Account) (*testsupport.FakeAPI, *form3.AccountsService) {
+	api := testsupport.NewFakeAPI(t, testsupport.FakeAPIOptions{}, accounts...)
+
+	return api, api.Service()
+}
+
+func testAccount(id string, names ...string) form3.Account {
+	return form3.Account{
+		ID:             id,
+		OrganisationID: "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c",
+		Type:           form3.AcctTypeAccounts,
+		Attributes: &form3.AccountAttributes{
+			Country:          form3.CountryCodeBelgium,
+			BankID:           "001",
+			Iban:             "BE71096123456769",
+			Name:             names,
+			AlternativeNames: []string{"Sam"},
+			JointAccount:     true,
+		},
+	}
+}
+
+func TestExporter_Export_csv(t *testing.T) {
+	_, service := newFakeAPI(t,
+		testAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "Samantha Holder", "S Holder"),
+		testAccount("b7a1c6f2-1e2c-4bde-8d1b-3c3f1f0e9a11", "John, Smith"),
+	)
+	out := &bytes.Buffer{}
+
+	count, err := NewExporter(service, ExportOptions{Columns: []Column{
+		{Header: "id", Field: "id"},
+		{Header: "bank_id", Field: "attributes.bank_id"},
+		{Header: "names", Field: "attributes.name"},
+		{Header: "first_name", Field: "attributes.name[0]"},
+		{Header: "second_name", Field: "attributes.name[1]"},
+		{Header: "joint", Field: "attributes.joint_account"},
+	}}).Export(context.Background(), out)
+
+	assert.Nil(t, err, "Export error should be nil")
+	assert.Equal(t, 2, count, "count incorrect")
+	assert.Equal(t, "id,bank_id,names,first_name,second_name,joint\n"+
+		"ad27e265-9605-4b4b-a0e5-3003ea9cc4dc,001,Samantha Holder|S Holder,Samantha Holder,S Holder,true\n"+
+		"b7a1c6f2-1e2c-4bde-8d1b-3c3f1f0e9a11,001,\"John, Smith\",\"John, Smith\",,true\n", out.String(), "output incorrect")
+}
+
+func TestExporter_Export_ndjson(t *testing.T) {
+	account := testAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "Samantha Holder")
+	_, service := newFakeAPI(t, account)
+	out := &bytes.Buffer{}
+
+	count, err := NewExporter(service, ExportOptions{Format: FormatNDJSON}).Export(context.Background(), out)
+
+	assert.Nil(t, err, "Export error should be nil")
+	assert.Equal(t, 1, count, "count incorrect")
+	exported := form3.Account{}
+	assert.Nil(t, json.Unmarshal(out.Bytes(), &exported), "output should be an account")
+	assert.Equal(t, account, exported, "exported account incorrect")
+}
+
+func TestExporter_Export_ndjsonColumns(t *testing.T) {
+	_, service := newFakeAPI(t, testAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "Samantha Holder", "S Holder"))
+	out := &bytes.Buffer{}
+
+	_, err := NewExporter(service, ExportOptions{Format: FormatNDJSON, Columns: []Column{
+		{Header: "id", Field: "id"},
+		{Header: "names", Field: "attributes.name"},
+		{Header: "bic", Field: "attributes.bic"},
+	}}).Export(context.Background(), out)
+
+	assert.Nil(t, err, "Export error should be nil")
+	assert.Equal(t, `{"id":"ad27e265-9605-4b4b-a0e5-3003ea9cc4dc","names":["Samantha Holder","S Holder"]}`+"\n", out.String(), "output incorrect")
+}
+
+func TestExporter_Export_invalidColumn(t *testing.T) {
+	_, service := newFakeAPI(t)
+
+	_, errUnknown := NewExporter(service, ExportOptions{Columns: []Column{{Header: "x", Field: "attributes.unknown"}}}).Export(context.Background(), io.Discard)
+	_, errIndex := NewExporter(service, ExportOptions{Columns: []Column{{Header: "x", Field: "attributes.bank_id[0]"}}}).Export(context.Background(), io.Discard)
+	_, errFormat := NewExporter(service, ExportOptions{Format: "xml"}).Export(context.Background(), io.Discard)
+
+	assert.EqualError(t, errUnknown, "column x: unknown field attributes.unknown", "unknown field error incorrect")
+	assert.EqualError(t, errIndex, "column x: field attributes.bank_id[0] is not multi-valued", "index error incorrect")
+	assert.EqualError(t, errFormat, "unknown format xml", "format error incorrect")
+}
+
+func TestExporter_Export_roundTrip(t *testing.T) {
+	_, source := newFakeAPI(t,
+		testAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "Samantha Holder", "S Holder"),
+		testAccount("b7a1c6f2-1e2c-4bde-8d1b-3c3f1f0e9a11", "John Smith"),
+	)
+	sandbox, target := newFakeAPI(t)
+	out := &bytes.Buffer{}
+
+	_, err := NewExporter(source, ExportOptions{}).Export(context.Background(), out)
+	assert.Nil(t, err, "Export error should be nil")
+	result, err := NewImporter(target, ImportOptions{}).Import(context.Background(), strings.NewReader(out.String()))
+
+	assert.Nil(t, err, "Import error should be nil")
+	assert.Equal(t, &ImportResult{Created: 2}, result, "result incorrect")
+	imported, _ := sandbox.Account("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+	assert.Equal(t, []string{"Samantha Holder", "S Holder"}, imported.Attributes.Name, "Name incorrect")
+	assert.Equal(t, []string{"Sam"}, imported.Attributes.AlternativeNames, "AlternativeNames incorrect")
+}
@@ -0,0 +1,374 @@
+package flatfile
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"form3-interview-accountapi/form3"
+	"form3-interview-accountapi/form3/internal/atomicfile"
+	"github.com/google/uuid"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type ImportOptions struct {
+	// Format of the file, CSV by default
+	Format Format
+	// Columns read, matched by header. DefaultColumns by default for CSV.
+	// NDJSON lines are whole accounts unless columns are set, then objects keyed by their headers
+	Columns []Column
+	// Separator splits multi-valued fields of a CSV cell, | by default
+	Separator string
+	// Validate is called after the default validation of each account when set
+	Validate func(account *form3.Account) error
+	// ProgressPath is a file keeping the number of records processed, to resume an interrupted import.
+	// It is not used when empty
+	ProgressPath string
+	// Rejects receives the records that could not be imported, in the same format with the reason.
+	// The CSV header is not written again to a file or buffer that already has content, e.g. appended to on resume
+	Rejects     io.Writer
+	CallOptions []form3.CallOption
+}
+
+// ImportResult counts the records of an import
+type ImportResult struct {
+	Created int
+	// Existing are the accounts already created, e.g. by an import interrupted before saving its progress
+	Existing int
+	Rejected int
+	// Skipped are the records processed by a previous run
+	Skipped int
+}
+
+type Importer struct {
+	service form3.AccountsAPI
+	opts    ImportOptions
+}
+
+// NewImporter returns an Importer instance.
+func NewImporter(service form3.AccountsAPI, opts ImportOptions) *Importer {
+	return &Importer{service: service, opts: opts}
+}
+
+// record is a record of the file, with the account parsed from it or the reason it could not be
+type record struct {
+	number  int
+	account *form3.Account
+	err     error
+	// raw is written to the rejects
+	cells []string
+	line  []byte
+}
+
+// Import reads the accounts of r, validates them and creates them, resuming after the records
+// of the progress file. Invalid records and failed creations are written to the rejects.
+func (i *Importer) Import(ctx context.Context, r io.Reader) (*ImportResult, error) {
+	done, err := i.loadProgress()
+	if err != nil {
+		return nil, fmt.Errorf("loading progress: %w", err)
+	}
+
+	next, reject, err := i.reader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{}
+	for {
+		rec, err := next()
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return result, err
+		}
+		if rec.number <= done {
+			result.Skipped++
+			continue
+		}
+
+		if rec.err == nil {
+			rec.err = Validate(rec.account)
+		}
+		if rec.err == nil && i.opts.Validate != nil {
+			rec.err = i.opts.Validate(rec.account)
+		}
+		if rec.err == nil {
+			_, resp, createErr := i.service.Create(ctx, rec.account, i.opts.CallOptions...)
+			if ctx.Err() != nil {
+				return result, ctx.Err()
+			}
+			switch {
+			case createErr == nil:
+				result.Created++
+			case resp != nil && resp.StatusCode == http.StatusConflict:
+				result.Existing++
+			default:
+				rec.err = createErr
+			}
+		}
+
+		if rec.err != nil {
+			result.Rejected++
+			if err = reject(rec); err != nil {
+				return result, fmt.Errorf("writing reject of record %d: %w", rec.number, err)
+			}
+		}
+
+		if err = i.saveProgress(rec.number); err != nil {
+			return result, fmt.Errorf("saving progress: %w", err)
+		}
+	}
+}
+
+// Validate checks the fields required to create an account
+func Validate(account *form3.Account) error {
+	if _, err := uuid.Parse(account.ID); err != nil {
+		return fmt.Errorf("id %q is not a UUID", account.ID)
+	}
+	if _, err := uuid.Parse(account.OrganisationID); err != nil {
+		return fmt.Errorf("organisation_id %q is not a UUID", account.OrganisationID)
+	}
+	if account.Type != form3.AcctTypeAccounts {
+		return fmt.Errorf("type %q is not %s", account.Type, form3.AcctTypeAccounts)
+	}
+	if account.Attributes == nil || account.Attributes.Country == "" {
+		return errors.New("country is required")
+	}
+	if len(account.Attributes.Name) == 0 || len(account.Attributes.Name) > 4 {
+		return errors.New("name must have between 1 and 4 values")
+	}
+
+	return nil
+}
+
+// reader returns the function reading the next record in the configured format and the one writing a reject
+func (i *Importer) reader(r io.Reader) (func() (*record, error), func(*record) error, error) {
+	separator := i.opts.Separator
+	if separator == "" {
+		separator = defaultSeparator
+	}
+	rejects := i.opts.Rejects
+	if rejects == nil {
+		rejects = io.Discard
+	}
+
+	switch i.opts.Format {
+	case FormatCSV, "":
+		fields, err := resolveColumns(i.opts.Columns)
+		if err != nil {
+			return nil, nil, err
+		}
+		return i.csvReader(r, fields, separator, rejects)
+	case FormatNDJSON:
+		var fields []field
+		if len(i.opts.Columns) > 0 {
+			var err error
+			if fields, err = resolveColumns(i.opts.Columns); err != nil {
+				return nil, nil, err
+			}
+		}
+		return ndjsonReader(r, fields, separator), ndjsonRejecter(rejects), nil
+	default:
+		return nil, nil, fmt.Errorf("unknown format %s", i.opts.Format)
+	}
+}
+
+func (i *Importer) csvReader(r io.Reader, fields []field, separator string, rejects io.Writer) (func() (*record, error), func(*record) error, error) {
+	csvReader := csv.NewReader(r)
+	header, err := csvReader.Read()
+	if err == io.EOF {
+		header = nil
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("reading header: %w", err)
+	}
+
+	// columns of the file by position, nil for the ones not configured
+	byHeader := map[string]field{}
+	for _, f := range fields {
+		byHeader[f.Header] = f
+	}
+	positions := make([]*field, len(header))
+	for position, name := range header {
+		if f, ok := byHeader[name]; ok {
+			positions[position] = &f
+		}
+	}
+
+	number := 0
+	next := func() (*record, error) {
+		if header == nil {
+			return nil, io.EOF
+		}
+		cells, err := csvReader.Read()
+		if err == io.EOF {
+			return nil, err
+		}
+		number++
+		rec := &record{number: number, cells: cells}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			rec.err = err
+			return rec, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		members := map[string]any{"type": string(form3.AcctTypeAccounts)}
+		for position, cell := range cells {
+			if positions[position] == nil {
+				continue
+			}
+			if err = positions[position].set(members, cell, separator); err != nil {
+				rec.err = err
+				return rec, nil
+			}
+		}
+		rec.account, rec.err = membersAccount(members)
+		return rec, nil
+	}
+
+	rejectsWriter := csv.NewWriter(rejects)
+	headerWritten := hasContent(rejects)
+	reject := func(rec *record) error {
+		if !headerWritten {
+			if err := rejectsWriter.Write(append(append([]string{}, header...), "error")); err != nil {
+				return err
+			}
+			headerWritten = true
+		}
+		cells := make([]string, len(header))
+		copy(cells, rec.cells)
+		if err := rejectsWriter.Write(append(cells, rec.err.Error())); err != nil {
+			return err
+		}
+		rejectsWriter.Flush()
+		return rejectsWriter.Error()
+	}
+
+	return next, reject, nil
+}
+
+// hasContent tells whether w is a file or buffer already written to
+func hasContent(w io.Writer) bool {
+	switch w := w.(type) {
+	case interface{ Stat() (os.FileInfo, error) }:
+		info, err := w.Stat()
+		return err == nil && info.Mode().IsRegular() && info.Size() > 0
+	case interface{ Len() int }:
+		return w.Len() > 0
+	}
+
+	return false
+}
+
+// ndjsonReader reads whole accounts, or objects keyed by the headers of fields when set
+func ndjsonReader(r io.Reader, fields []field, separator string) func() (*record, error) {
+	lines := bufio.NewReader(r)
+	number := 0
+
+	return func() (*record, error) {
+		var line []byte
+		for len(line) == 0 {
+			var err error
+			line, err = lines.ReadBytes('\n')
+			if err == io.EOF && len(bytes.TrimSpace(line)) == 0 {
+				return nil, io.EOF
+			}
+			if err != nil && err != io.EOF {
+				return nil, err
+			}
+			line = bytes.TrimSpace(line)
+		}
+		number++
+		rec := &record{number: number, line: line}
+
+		if len(fields) == 0 {
+			account := &form3.Account{}
+			if err := json.Unmarshal(line, account); err != nil {
+				rec.err = err
+				return rec, nil
+			}
+			rec.account = account
+			return rec, nil
+		}
+
+		object := map[string]any{}
+		if err := json.Unmarshal(line, &object); err != nil {
+			rec.err = err
+			return rec, nil
+		}
+		members := map[string]any{"type": string(form3.AcctTypeAccounts)}
+		for _, f := range fields {
+			if err := f.set(members, object[f.Header], separator); err != nil {
+				rec.err = err
+				return rec, nil
+			}
+		}
+		rec.account, rec.err = membersAccount(members)
+		return rec, nil
+	}
+}
+
+func ndjsonRejecter(rejects io.Writer) func(*record) error {
+	encoder := json.NewEncoder(rejects)
+
+	return func(rec *record) error {
+		var raw any = string(rec.line)
+		if json.Valid(rec.line) {
+			raw = json.RawMessage(rec.line)
+		}
+		return encoder.Encode(struct {
+			Record any    `json:"record"`
+			Line   int    `json:"line"`
+			Error  string `json:"error"`
+		}{raw, rec.number, rec.err.Error()})
+	}
+}
+
+func membersAccount(members map[string]any) (*form3.Account, error) {
+	data, err := json.Marshal(members)
+	if err != nil {
+		return nil, err
+	}
+
+	account := &form3.Account{}
+	if err = json.Unmarshal(data, account); err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+func (i *Importer) loadProgress() (int, error) {
+	if i.opts.ProgressPath == "" {
+		return 0, nil
+	}
+
+	data, err := os.ReadFile(i.opts.ProgressPath)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.Atoi(strings.TrimSpace(string(data)))
+}
+
+// saveProgress writes the number of records processed, replacing the progress file at once
+func (i *Importer) saveProgress(done int) error {
+	if i.opts.ProgressPath == "" {
+		return nil
+	}
+
+	return atomicfile.WriteFile(i.opts.ProgressPath, []byte(strconv.Itoa(done)))
+}
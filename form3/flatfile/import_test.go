@@ -0,0 +1,150 @@
+package flatfile
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"form3-interview-accountapi/form3"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const importColumnsCSV = "id,organisation_id,country,name,joint\n" +
+	"ad27e265-9605-4b4b-a0e5-3003ea9cc4dc,eb0bd6f5-c3f5-44b2-b677-acd23cdde73c,BE,Samantha Holder|S Holder,true\n" +
+	"not-a-uuid,eb0bd6f5-c3f5-44b2-b677-acd23cdde73c,BE,John Smith,false\n" +
+	"b7a1c6f2-1e2c-4bde-8d1b-3c3f1f0e9a11,eb0bd6f5-c3f5-44b2-b677-acd23cdde73c,BE,John Smith,maybe\n" +
+	"c0f5b2d4-6a9e-4f4b-9b8e-6e7a2c1d3f22,eb0bd6f5-c3f5-44b2-b677-acd23cdde73c,BE,Jane Doe,\n"
+
+var importColumns = []Column{
+	{Header: "id", Field: "id"},
+	{Header: "organisation_id", Field: "organisation_id"},
+	{Header: "country", Field: "attributes.country"},
+	{Header: "name", Field: "attributes.name"},
+	{Header: "joint", Field: "attributes.joint_account"},
+}
+
+func TestImporter_Import_csv(t *testing.T) {
+	api, service := newFakeAPI(t)
+	rejects := &bytes.Buffer{}
+
+	result, err := NewImporter(service, ImportOptions{Columns: importColumns, Rejects: rejects}).
+		Import(context.Background(), strings.NewReader(importColumnsCSV))
+
+	assert.Nil(t, err, "Import error should be nil")
+	assert.Equal(t, &ImportResult{Created: 2, Rejected: 2}, result, "result incorrect")
+	assert.Equal(t, 2, api.Len(), "accounts created incorrect")
+	assert.Equal(t, "id,organisation_id,country,name,joint,error\n"+
+		"not-a-uuid,eb0bd6f5-c3f5-44b2-b677-acd23cdde73c,BE,John Smith,false,\"id \"\"not-a-uuid\"\" is not a UUID\"\n"+
+		"b7a1c6f2-1e2c-4bde-8d1b-3c3f1f0e9a11,eb0bd6f5-c3f5-44b2-b677-acd23cdde73c,BE,John Smith,maybe,\"column joint: strconv.ParseBool: parsing \"\"maybe\"\": invalid syntax\"\n",
+		rejects.String(), "rejects incorrect")
+}
+
+func TestImporter_Import_ndjson(t *testing.T) {
+	api, service := newFakeAPI(t)
+	rejects := &bytes.Buffer{}
+	input := `{"id":"ad27e265-9605-4b4b-a0e5-3003ea9cc4dc","organisation_id":"eb0bd6f5-c3f5-44b2-b677-acd23cdde73c","type":"accounts","attributes":{"country":"BE","name":["Samantha Holder"]}}` + "\n" +
+		"\n" +
+		`{"id":"b7a1c6f2-1e2c-4bde-8d1b-3c3f1f0e9a11","organisation_id":"eb0bd6f5-c3f5-44b2-b677-acd23cdde73c","type":"accounts","attributes":{"country":"BE"}}` + "\n" +
+		"not json"
+
+	result, err := NewImporter(service, ImportOptions{Format: FormatNDJSON, Rejects: rejects}).
+		Import(context.Background(), strings.NewReader(input))
+
+	assert.Nil(t, err, "Import error should be nil")
+	assert.Equal(t, &ImportResult{Created: 1, Rejected: 2}, result, "result incorrect")
+	assert.Equal(t, 1, api.Len(), "accounts created incorrect")
+	assert.Equal(t, `{"record":{"id":"b7a1c6f2-1e2c-4bde-8d1b-3c3f1f0e9a11","organisation_id":"eb0bd6f5-c3f5-44b2-b677-acd23cdde73c","type":"accounts","attributes":{"country":"BE"}},"line":2,"error":"name must have between 1 and 4 values"}`+"\n"+
+		`{"record":"not json","line":3,"error":"invalid character 'o' in literal null (expecting 'u')"}`+"\n",
+		rejects.String(), "rejects incorrect")
+}
+
+func TestImporter_Import_ndjsonColumns(t *testing.T) {
+	api, service := newFakeAPI(t)
+	input := `{"id":"ad27e265-9605-4b4b-a0e5-3003ea9cc4dc","organisation_id":"eb0bd6f5-c3f5-44b2-b677-acd23cdde73c","country":"BE","name":["Samantha Holder","S Holder"],"joint":"true"}`
+
+	result, err := NewImporter(service, ImportOptions{Format: FormatNDJSON, Columns: importColumns}).
+		Import(context.Background(), strings.NewReader(input))
+
+	assert.Nil(t, err, "Import error should be nil")
+	assert.Equal(t, &ImportResult{Created: 1}, result, "result incorrect")
+	created, _ := api.Account("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+	assert.Equal(t, []string{"Samantha Holder", "S Holder"}, created.Attributes.Name, "name incorrect")
+	assert.True(t, created.Attributes.JointAccount, "joint_account incorrect")
+}
+
+func TestImporter_Import_resumesFromProgress(t *testing.T) {
+	api, service := newFakeAPI(t)
+	progressPath := filepath.Join(t.TempDir(), "progress")
+	importer := NewImporter(service, ImportOptions{Columns: importColumns, ProgressPath: progressPath})
+
+	_ = os.WriteFile(progressPath, []byte("2"), 0o600)
+	result, err := importer.Import(context.Background(), strings.NewReader(importColumnsCSV))
+
+	assert.Nil(t, err, "Import error should be nil")
+	assert.Equal(t, &ImportResult{Created: 1, Rejected: 1, Skipped: 2}, result, "result incorrect")
+	assert.Equal(t, 1, api.Len(), "accounts created incorrect")
+	progress, _ := os.ReadFile(progressPath)
+	assert.Equal(t, "4", string(progress), "progress incorrect")
+
+	_ = os.WriteFile(progressPath, []byte("0"), 0o600)
+	result, err = importer.Import(context.Background(), strings.NewReader(importColumnsCSV))
+
+	assert.Nil(t, err, "Import error should be nil")
+	assert.Equal(t, &ImportResult{Created: 1, Existing: 1, Rejected: 2}, result, "result of the rerun incorrect")
+}
+
+func TestImporter_Import_resumeAppendsRejects(t *testing.T) {
+	_, service := newFakeAPI(t)
+	dir := t.TempDir()
+	progressPath, rejectsPath := filepath.Join(dir, "progress"), filepath.Join(dir, "rejects.csv")
+	importRecords := func(records string) {
+		rejects, err := os.OpenFile(rejectsPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			t.Fatalf("Error opening rejects: %v", err)
+		}
+		defer rejects.Close()
+		_, err = NewImporter(service, ImportOptions{Columns: importColumns, ProgressPath: progressPath, Rejects: rejects}).
+			Import(context.Background(), strings.NewReader(records))
+		assert.Nil(t, err, "Import error should be nil")
+	}
+
+	lines := strings.SplitAfter(importColumnsCSV, "\n")
+	importRecords(strings.Join(lines[:3], ""))
+	importRecords(importColumnsCSV)
+
+	rejects, _ := os.ReadFile(rejectsPath)
+	assert.Equal(t, 1, strings.Count(string(rejects), "id,organisation_id,country,name,joint,error\n"), "header should be written once")
+	assert.Equal(t, 3, strings.Count(string(rejects), "\n"), "rejects incorrect")
+}
+
+func TestImporter_Import_customValidate(t *testing.T) {
+	api, service := newFakeAPI(t)
+
+	result, err := NewImporter(service, ImportOptions{
+		Columns: importColumns,
+		Validate: func(account *form3.Account) error {
+			if account.Attributes.Name[0] == "Jane Doe" {
+				return errors.New("blocked")
+			}
+			return nil
+		},
+	}).Import(context.Background(), strings.NewReader(importColumnsCSV))
+
+	assert.Nil(t, err, "Import error should be nil")
+	assert.Equal(t, &ImportResult{Created: 1, Rejected: 3}, result, "result incorrect")
+	assert.Equal(t, 1, api.Creates(), "creates incorrect")
+}
+
+func TestValidate(t *testing.T) {
+	account := testAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "Samantha Holder")
+	noCountry := testAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "Samantha Holder")
+	noCountry.Attributes.Country = ""
+	tooManyNames := testAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "a", "b", "c", "d", "e")
+
+	assert.Nil(t, Validate(&account), "valid account error should be nil")
+	assert.EqualError(t, Validate(&noCountry), "country is required", "country error incorrect")
+	assert.EqualError(t, Validate(&tooManyNames), "name must have between 1 and 4 values", "name error incorrect")
+}
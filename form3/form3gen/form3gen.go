@@ -0,0 +1,192 @@
+// Package form3gen generates random but valid accounts for property-based and fuzz tests.
+//
+// Generators are deterministic: the same seed, or the same bytes, always give the same accounts,
+// so a failing case can be reproduced from its seed.
+package form3gen
+
+import (
+	"encoding/binary"
+	"form3-interview-accountapi/form3"
+	"github.com/google/uuid"
+	"math/rand"
+	"reflect"
+	"strings"
+)
+
+const (
+	maxNames            = 4
+	maxAlternativeNames = 3
+	// maxNameLength bounds the names, alternative names and secondary identification
+	maxNameLength = 140
+
+	defaultOptionalRate = 0.5
+)
+
+type Options struct {
+	// Countries are the countries of the accounts, all the supported ones by default
+	Countries []form3.CountryCode
+	// OptionalRate is the probability of setting each optional attribute, 0.5 by default.
+	// Negative values never set them
+	OptionalRate float64
+}
+
+type Generator struct {
+	rand *rand.Rand
+	opts Options
+}
+
+// New returns a Generator instance producing the accounts of seed.
+func New(seed int64, opts Options) *Generator {
+	return NewFromRand(rand.New(rand.NewSource(seed)), opts)
+}
+
+// NewFromRand returns a Generator instance drawing from r, e.g. the one given by testing/quick.
+func NewFromRand(r *rand.Rand, opts Options) *Generator {
+	if len(opts.Countries) == 0 {
+		opts.Countries = Countries()
+	}
+	if opts.OptionalRate == 0 {
+		opts.OptionalRate = defaultOptionalRate
+	}
+
+	return &Generator{rand: r, opts: opts}
+}
+
+// NewFromBytes returns a Generator instance drawing from data, for fuzz tests.
+// Once data is exhausted every choice is the smallest one, so the fuzzer minimising data
+// gives simpler accounts: the first country, short names and no optional attributes.
+func NewFromBytes(data []byte, opts Options) *Generator {
+	return NewFromRand(rand.New(&bytesSource{data: data}), opts)
+}
+
+// Account returns a valid account of one of the countries of the options
+func (g *Generator) Account() *form3.Account {
+	return g.AccountIn(g.opts.Countries[g.rand.Intn(len(g.opts.Countries))])
+}
+
+// AccountIn returns a valid account of the country, which must be one of Countries
+func (g *Generator) AccountIn(countryCode form3.CountryCode) *form3.Account {
+	spec, ok := countries[countryCode]
+	if !ok {
+		panic("form3gen: unsupported country " + string(countryCode))
+	}
+
+	bankID := randomDigits(g.rand, spec.bankIDDigits)
+	accountNumber := randomDigits(g.rand, spec.accountDigits)
+	if spec.completeAccount != nil {
+		accountNumber = spec.completeAccount(accountNumber)
+	}
+	bic := randomBIC(g.rand, spec.code)
+
+	attributes := &form3.AccountAttributes{
+		Country:    spec.code,
+		BankID:     bankID,
+		BankIDCode: spec.bankIDCode,
+		Name:       g.names(maxNames),
+	}
+	if spec.bicRequired || g.optional() {
+		attributes.Bic = bic
+	}
+	if g.optional() {
+		attributes.AccountNumber = accountNumber
+		attributes.Iban = IBAN(spec.code, spec.bban(bankID, accountNumber, bic))
+	}
+	if g.optional() {
+		attributes.BaseCurrency = spec.currency
+	}
+	if g.optional() {
+		attributes.AlternativeNames = g.names(maxAlternativeNames)
+	}
+	if g.optional() {
+		attributes.AccountClassification = form3.AcctClassificationPersonal
+		if g.rand.Intn(2) == 1 {
+			attributes.AccountClassification = form3.AcctClassificationBusiness
+		}
+	}
+	if g.optional() {
+		attributes.SecondaryIdentification = g.text(maxNameLength)
+	}
+	if g.optional() {
+		attributes.JointAccount = true
+	}
+	if g.optional() {
+		attributes.AccountMatchingOptOut = true
+	}
+
+	return &form3.Account{
+		ID:             g.uuid(),
+		OrganisationID: g.uuid(),
+		Type:           form3.AcctTypeAccounts,
+		Attributes:     attributes,
+	}
+}
+
+// optional draws whether an optional attribute is set. The smallest draw does not set it
+func (g *Generator) optional() bool {
+	return g.rand.Float64() >= 1-g.opts.OptionalRate
+}
+
+func (g *Generator) uuid() string {
+	id, err := uuid.NewRandomFromReader(g.rand)
+	if err != nil {
+		panic(err)
+	}
+
+	return id.String()
+}
+
+// names returns between 1 and max names
+func (g *Generator) names(max int) []string {
+	names := make([]string, 1+g.rand.Intn(max))
+	for i := range names {
+		names[i] = g.text(maxNameLength)
+	}
+
+	return names
+}
+
+// text returns words between 1 and maxLength characters long, with no leading or trailing space
+func (g *Generator) text(maxLength int) string {
+	length := 1 + g.rand.Intn(maxLength)
+
+	var text strings.Builder
+	for text.Len() < length {
+		if text.Len() > 0 && text.Len() < length-1 && g.rand.Intn(6) == 0 {
+			text.WriteByte(' ')
+			continue
+		}
+		letter := byte('a' + g.rand.Intn(26))
+		if text.Len() == 0 {
+			letter -= 'a' - 'A'
+		}
+		text.WriteByte(letter)
+	}
+
+	return text.String()
+}
+
+// Account is a form3.Account implementing quick.Generator, so testing/quick can generate valid accounts:
+//
+//	quick.Check(func(account form3gen.Account) bool { ... }, nil)
+type Account struct {
+	*form3.Account
+}
+
+func (Account) Generate(r *rand.Rand, size int) reflect.Value {
+	return reflect.ValueOf(Account{NewFromRand(r, Options{}).Account()})
+}
+
+// bytesSource is a rand.Source reading its values from data, then zeros
+type bytesSource struct {
+	data []byte
+}
+
+func (s *bytesSource) Int63() int64 {
+	var buf [8]byte
+	n := copy(buf[:], s.data)
+	s.data = s.data[n:]
+
+	return int64(binary.BigEndian.Uint64(buf[:]) & (1<<63 - 1))
+}
+
+func (s *bytesSource) Seed(seed int64) {}
@@ -0,0 +1,135 @@
+package form3gen
+
+import (
+	"form3-interview-accountapi/form3"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"testing/quick"
+)
+
+// checkAccount returns the first broken invariant of a generated account, empty when it is valid
+func checkAccount(account *form3.Account) string {
+	attributes := account.Attributes
+	spec, ok := countries[attributes.Country]
+	_, idErr := uuid.Parse(account.ID)
+	_, organisationIDErr := uuid.Parse(account.OrganisationID)
+	switch {
+	case idErr != nil:
+		return "invalid id " + account.ID
+	case organisationIDErr != nil:
+		return "invalid organisation_id " + account.OrganisationID
+	case account.Type != form3.AcctTypeAccounts:
+		return "invalid type"
+	case !ok:
+		return "unsupported country " + string(attributes.Country)
+	case len(attributes.BankID) != spec.bankIDDigits || attributes.BankIDCode != spec.bankIDCode:
+		return "invalid bank id " + attributes.BankID
+	case spec.bicRequired && attributes.Bic == "":
+		return "missing bic"
+	case attributes.Bic != "" && (!ValidBIC(attributes.Bic) || attributes.Bic[4:6] != string(attributes.Country)):
+		return "invalid bic " + attributes.Bic
+	case attributes.Iban != "" && (!ValidIBAN(attributes.Iban) || !strings.HasPrefix(attributes.Iban, string(attributes.Country))):
+		return "invalid iban " + attributes.Iban
+	case attributes.Iban != "" && !strings.Contains(attributes.Iban, attributes.BankID+attributes.AccountNumber) &&
+		!strings.Contains(attributes.Iban, attributes.AccountNumber):
+		return "iban " + attributes.Iban + " does not hold the account number"
+	case len(attributes.Name) < 1 || len(attributes.Name) > maxNames:
+		return "invalid name count"
+	case len(attributes.AlternativeNames) > maxAlternativeNames:
+		return "invalid alternative name count"
+	}
+
+	for _, name := range append(append([]string{attributes.SecondaryIdentification}, attributes.Name...), attributes.AlternativeNames...) {
+		if len(name) > maxNameLength || strings.TrimSpace(name) != name {
+			return "invalid name " + name
+		}
+	}
+
+	return ""
+}
+
+func TestGenerator_quick(t *testing.T) {
+	property := func(account Account) bool {
+		if problem := checkAccount(account.Account); problem != "" {
+			t.Log(problem)
+			return false
+		}
+		return true
+	}
+
+	assert.Nil(t, quick.Check(property, &quick.Config{MaxCount: 1000}), "generated accounts should be valid")
+}
+
+func TestGenerator_deterministic(t *testing.T) {
+	first := New(42, Options{})
+	second := New(42, Options{})
+
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, first.Account(), second.Account(), "same seed should give the same accounts")
+	}
+	assert.NotEqual(t, New(1, Options{}).Account(), New(2, Options{}).Account(), "seeds should give different accounts")
+}
+
+func TestGenerator_options(t *testing.T) {
+	all := New(7, Options{Countries: []form3.CountryCode{form3.CountryCodeUnitedKingdom}, OptionalRate: 1})
+	none := New(7, Options{OptionalRate: -1})
+
+	for i := 0; i < 50; i++ {
+		account := all.Account()
+		assert.Equal(t, form3.CountryCodeUnitedKingdom, account.Attributes.Country, "Country incorrect")
+		assert.NotEmpty(t, account.Attributes.Iban, "Iban should be set")
+		assert.NotEmpty(t, account.Attributes.AlternativeNames, "AlternativeNames should be set")
+		assert.Equal(t, form3.BaseCurrencyGbp, account.Attributes.BaseCurrency, "BaseCurrency incorrect")
+
+		account = none.Account()
+		assert.Empty(t, account.Attributes.Iban, "Iban should not be set")
+		assert.Empty(t, account.Attributes.AlternativeNames, "AlternativeNames should not be set")
+	}
+}
+
+func TestNewFromBytes_minimal(t *testing.T) {
+	account := NewFromBytes(nil, Options{}).Account()
+
+	assert.Empty(t, checkAccount(account), "account should be valid")
+	assert.Equal(t, form3.CountryCodeBelgium, account.Attributes.Country, "Country incorrect")
+	assert.Equal(t, []string{"A"}, account.Attributes.Name, "Name incorrect")
+	assert.Empty(t, account.Attributes.Iban, "Iban should not be set")
+}
+
+func FuzzGenerator(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte("form3"))
+	f.Add([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		account := NewFromBytes(data, Options{}).Account()
+		if problem := checkAccount(account); problem != "" {
+			t.Fatal(problem)
+		}
+	})
+}
+
+func TestIBAN(t *testing.T) {
+	assert.Equal(t, "GB82WEST12345698765432", IBAN(form3.CountryCodeUnitedKingdom, "WEST12345698765432"), "GB IBAN incorrect")
+	assert.Equal(t, "BE71096123456769", IBAN(form3.CountryCodeBelgium, countries[form3.CountryCodeBelgium].bban("096", "1234567", "")), "BE IBAN incorrect")
+	assert.Equal(t, "FR7630006000011234567890189", IBAN(form3.CountryCodeFrance, countries[form3.CountryCodeFrance].bban("3000600001", "12345678901", "")), "FR IBAN incorrect")
+	assert.Equal(t, "DE89370400440532013000", IBAN(form3.CountryCodeGermany, countries[form3.CountryCodeGermany].bban("37040044", "0532013000", "")), "DE IBAN incorrect")
+	assert.Equal(t, "221020145685", countries[form3.CountryCodeEstonia].completeAccount("22102014568"), "EE account number incorrect")
+	assert.Equal(t, "EE382200221020145685", IBAN(form3.CountryCodeEstonia, countries[form3.CountryCodeEstonia].bban("", "221020145685", "")), "EE IBAN incorrect")
+}
+
+func TestValidIBAN(t *testing.T) {
+	assert.True(t, ValidIBAN("GB82WEST12345698765432"), "valid IBAN")
+	assert.False(t, ValidIBAN("GB83WEST12345698765432"), "wrong check digits")
+	assert.False(t, ValidIBAN("gb82west12345698765432"), "lower case")
+	assert.False(t, ValidIBAN("GB8"), "too short")
+}
+
+func TestValidBIC(t *testing.T) {
+	assert.True(t, ValidBIC("NWBKGB22"), "valid BIC")
+	assert.True(t, ValidBIC("DEUTDEFF500"), "valid BIC with branch")
+	assert.False(t, ValidBIC("NWBKGB2"), "too short")
+	assert.False(t, ValidBIC("NWB1GB22"), "digit in bank code")
+}
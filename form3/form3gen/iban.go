@@ -0,0 +1,189 @@
+package form3gen
+
+import (
+	"fmt"
+	"form3-interview-accountapi/form3"
+	"math/big"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// country describes how the bank identifiers of a country are formed
+type country struct {
+	code          form3.CountryCode
+	bankIDCode    form3.BankIDCode
+	currency      form3.BaseCurrency
+	bankIDDigits  int
+	accountDigits int
+	// bicRequired is set for the countries where accounts are identified by BIC instead of bank ID
+	bicRequired bool
+	// completeAccount adds the national check digits to the random digits of an account number when set
+	completeAccount func(digits string) string
+	// bban builds the domestic account number of the IBAN
+	bban func(bankID string, accountNumber string, bic string) string
+}
+
+var countries = map[form3.CountryCode]country{
+	form3.CountryCodeBelgium: {
+		code: form3.CountryCodeBelgium, bankIDCode: form3.BankIDCodeBelgium, currency: form3.BaseCurrencyEur,
+		bankIDDigits: 3, accountDigits: 7,
+		bban: func(bankID string, accountNumber string, bic string) string {
+			check := mod97(bankID + accountNumber)
+			if check == 0 {
+				check = 97
+			}
+			return fmt.Sprintf("%s%s%02d", bankID, accountNumber, check)
+		},
+	},
+	form3.CountryCodeEstonia: {
+		code: form3.CountryCodeEstonia, currency: form3.BaseCurrencyEur,
+		accountDigits: 11, bicRequired: true,
+		completeAccount: func(digits string) string {
+			// the account number starts with the bank code, which does not start with 0
+			if digits[0] == '0' {
+				digits = "1" + digits[1:]
+			}
+			return digits + estonianCheckDigit(digits)
+		},
+		bban: func(bankID string, accountNumber string, bic string) string {
+			return accountNumber[:2] + strings.Repeat("0", 14-len(accountNumber)) + accountNumber
+		},
+	},
+	form3.CountryCodeFrance: {
+		code: form3.CountryCodeFrance, bankIDCode: form3.BankIDCodeFrance, currency: form3.BaseCurrencyEur,
+		bankIDDigits: 10, accountDigits: 11,
+		bban: func(bankID string, accountNumber string, bic string) string {
+			// the RIB key checks the bank, branch and account numbers
+			bank, _ := strconv.ParseInt(bankID[:5], 10, 64)
+			branch, _ := strconv.ParseInt(bankID[5:], 10, 64)
+			account, _ := strconv.ParseInt(accountNumber, 10, 64)
+			key := 97 - (89*bank+15*branch+3*account)%97
+			return fmt.Sprintf("%s%s%02d", bankID, accountNumber, key)
+		},
+	},
+	form3.CountryCodeGermany: {
+		code: form3.CountryCodeGermany, bankIDCode: form3.BankIDCodeGermany, currency: form3.BaseCurrencyEur,
+		bankIDDigits: 8, accountDigits: 10,
+		bban: func(bankID string, accountNumber string, bic string) string {
+			return bankID + accountNumber
+		},
+	},
+	form3.CountryCodeUnitedKingdom: {
+		code: form3.CountryCodeUnitedKingdom, bankIDCode: form3.BankIDCodeUnitedKingdom, currency: form3.BaseCurrencyGbp,
+		bankIDDigits: 6, accountDigits: 8,
+		bban: func(bankID string, accountNumber string, bic string) string {
+			return bic[:4] + bankID + accountNumber
+		},
+	},
+}
+
+// Countries returns the countries the generator supports
+func Countries() []form3.CountryCode {
+	return []form3.CountryCode{
+		form3.CountryCodeBelgium,
+		form3.CountryCodeEstonia,
+		form3.CountryCodeFrance,
+		form3.CountryCodeGermany,
+		form3.CountryCodeUnitedKingdom,
+	}
+}
+
+// IBAN returns the IBAN of a domestic account number, computing its check digits
+func IBAN(country form3.CountryCode, bban string) string {
+	check := 98 - mod97(ibanDigits(bban+string(country)+"00"))
+	return fmt.Sprintf("%s%02d%s", country, check, bban)
+}
+
+// ValidIBAN reports whether the check digits of the IBAN are correct
+func ValidIBAN(iban string) bool {
+	if len(iban) < 5 || len(iban) > 34 {
+		return false
+	}
+	for _, c := range iban {
+		if !(c >= '0' && c <= '9' || c >= 'A' && c <= 'Z') {
+			return false
+		}
+	}
+
+	return mod97(ibanDigits(iban[4:]+iban[:4])) == 1
+}
+
+// ValidBIC reports whether the BIC has the shape of a SWIFT BIC: bank, country, location and optional branch
+func ValidBIC(bic string) bool {
+	if len(bic) != 8 && len(bic) != 11 {
+		return false
+	}
+	for i, c := range bic {
+		letter := c >= 'A' && c <= 'Z'
+		digit := c >= '0' && c <= '9'
+		if i < 6 && !letter || i >= 6 && !letter && !digit {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ibanDigits replaces the letters by two digits, A being 10
+func ibanDigits(s string) string {
+	var digits strings.Builder
+	for _, c := range s {
+		if c >= 'A' && c <= 'Z' {
+			digits.WriteString(strconv.Itoa(int(c-'A') + 10))
+			continue
+		}
+		digits.WriteRune(c)
+	}
+
+	return digits.String()
+}
+
+func mod97(digits string) int {
+	n, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return -1
+	}
+
+	return int(new(big.Int).Mod(n, big.NewInt(97)).Int64())
+}
+
+// estonianCheckDigit computes the 7-3-1 check digit of an Estonian account number
+func estonianCheckDigit(digits string) string {
+	weights := []int{7, 3, 1}
+	sum := 0
+	for i := 0; i < len(digits); i++ {
+		sum += int(digits[len(digits)-1-i]-'0') * weights[i%3]
+	}
+
+	return strconv.Itoa((10 - sum%10) % 10)
+}
+
+func randomDigits(r *rand.Rand, n int) string {
+	digits := make([]byte, n)
+	for i := range digits {
+		digits[i] = byte('0' + r.Intn(10))
+	}
+
+	return string(digits)
+}
+
+func randomLetters(r *rand.Rand, n int) string {
+	letters := make([]byte, n)
+	for i := range letters {
+		letters[i] = byte('A' + r.Intn(26))
+	}
+
+	return string(letters)
+}
+
+// randomBIC returns a BIC of the country, with a branch code one time out of two
+func randomBIC(r *rand.Rand, country form3.CountryCode) string {
+	location := randomLetters(r, 1) + randomDigits(r, 1)
+	bic := randomLetters(r, 4) + string(country) + location
+	if r.Intn(2) == 1 {
+		bic += randomDigits(r, 3)
+	}
+
+	return bic
+}
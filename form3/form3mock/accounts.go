@@ -0,0 +1,137 @@
+package form3mock
+
+import (
+	"context"
+	"errors"
+	"form3-interview-accountapi/form3"
+)
+
+// AccountsAPI is a mock of form3.AccountsAPI
+type AccountsAPI struct {
+	mock
+}
+
+var _ form3.AccountsAPI = (*AccountsAPI)(nil)
+
+// NewAccountsAPI returns an AccountsAPI instance.
+// Its expectations are asserted when the test of t ends
+func NewAccountsAPI(t TestingT) *AccountsAPI {
+	m := &AccountsAPI{mock{t: t}}
+	t.Cleanup(func() { m.AssertExpectations() })
+
+	return m
+}
+
+// Create matches the account and returns (*form3.Account, *form3.RestClientResponse, error)
+func (m *AccountsAPI) Create(ctx context.Context, data *form3.Account, opts ...form3.CallOption) (*form3.Account, *form3.RestClientResponse, error) {
+	m.t.Helper()
+	values, ok := m.called("Create", data)
+
+	return result[*form3.Account](values, 0), result[*form3.RestClientResponse](values, 1), errorResult(values, ok, 2)
+}
+
+// Get matches the id and returns (*form3.Account, *form3.RestClientResponse, error)
+func (m *AccountsAPI) Get(ctx context.Context, id string, opts ...form3.CallOption) (*form3.Account, *form3.RestClientResponse, error) {
+	m.t.Helper()
+	values, ok := m.called("Get", id)
+
+	return result[*form3.Account](values, 0), result[*form3.RestClientResponse](values, 1), errorResult(values, ok, 2)
+}
+
+// List matches the list options and returns ([]form3.Account, *form3.RestClientResponse, error)
+func (m *AccountsAPI) List(ctx context.Context, listOpts *form3.ListOptions, opts ...form3.CallOption) ([]form3.Account, *form3.RestClientResponse, error) {
+	m.t.Helper()
+	values, ok := m.called("List", listOpts)
+
+	return result[[]form3.Account](values, 0), result[*form3.RestClientResponse](values, 1), errorResult(values, ok, 2)
+}
+
+// ListEach matches the list options and returns ([]form3.Account, error),
+// the accounts are passed to fn before the error is returned
+func (m *AccountsAPI) ListEach(ctx context.Context, listOpts *form3.ListOptions, fn func(account *form3.Account) error, opts ...form3.CallOption) error {
+	m.t.Helper()
+	values, ok := m.called("ListEach", listOpts)
+
+	accounts := result[[]form3.Account](values, 0)
+	for i := range accounts {
+		if err := fn(&accounts[i]); err != nil {
+			if errors.Is(err, form3.ErrStopListing) {
+				return nil
+			}
+			return err
+		}
+	}
+
+	return errorResult(values, ok, 1)
+}
+
+// Update matches the id, version and attributes and returns (*form3.Account, *form3.RestClientResponse, error)
+func (m *AccountsAPI) Update(ctx context.Context, id string, version int, attributes *form3.AccountAttributesPatch, opts ...form3.CallOption) (*form3.Account, *form3.RestClientResponse, error) {
+	m.t.Helper()
+	values, ok := m.called("Update", id, version, attributes)
+
+	return result[*form3.Account](values, 0), result[*form3.RestClientResponse](values, 1), errorResult(values, ok, 2)
+}
+
+// Delete matches the id and version and returns (*form3.RestClientResponse, error)
+func (m *AccountsAPI) Delete(ctx context.Context, id string, version int, opts ...form3.CallOption) (*form3.RestClientResponse, error) {
+	m.t.Helper()
+	values, ok := m.called("Delete", id, version)
+
+	return result[*form3.RestClientResponse](values, 0), errorResult(values, ok, 1)
+}
+
+// DeleteLatest matches the id and returns (*form3.RestClientResponse, error)
+func (m *AccountsAPI) DeleteLatest(ctx context.Context, id string, opts ...form3.DeleteLatestOption) (*form3.RestClientResponse, error) {
+	m.t.Helper()
+	values, ok := m.called("DeleteLatest", id)
+
+	return result[*form3.RestClientResponse](values, 0), errorResult(values, ok, 1)
+}
+
+// Transition matches the id and status and returns (*form3.Account, *form3.RestClientResponse, error)
+func (m *AccountsAPI) Transition(ctx context.Context, id string, to form3.AccountStatus, opts ...form3.CallOption) (*form3.Account, *form3.RestClientResponse, error) {
+	m.t.Helper()
+	values, ok := m.called("Transition", id, to)
+
+	return result[*form3.Account](values, 0), result[*form3.RestClientResponse](values, 1), errorResult(values, ok, 2)
+}
+
+// Confirm matches the id and returns (*form3.Account, *form3.RestClientResponse, error)
+func (m *AccountsAPI) Confirm(ctx context.Context, id string, opts ...form3.CallOption) (*form3.Account, *form3.RestClientResponse, error) {
+	m.t.Helper()
+	values, ok := m.called("Confirm", id)
+
+	return result[*form3.Account](values, 0), result[*form3.RestClientResponse](values, 1), errorResult(values, ok, 2)
+}
+
+// Close matches the id and returns (*form3.Account, *form3.RestClientResponse, error)
+func (m *AccountsAPI) Close(ctx context.Context, id string, opts ...form3.CallOption) (*form3.Account, *form3.RestClientResponse, error) {
+	m.t.Helper()
+	values, ok := m.called("Close", id)
+
+	return result[*form3.Account](values, 0), result[*form3.RestClientResponse](values, 1), errorResult(values, ok, 2)
+}
+
+// WaitForStatus matches the id, wait options and target statuses as a []form3.AccountStatus,
+// and returns (*form3.Account, error)
+func (m *AccountsAPI) WaitForStatus(ctx context.Context, id string, opts *form3.WaitOptions, targetStatuses ...form3.AccountStatus) (*form3.Account, error) {
+	m.t.Helper()
+	values, ok := m.called("WaitForStatus", id, opts, targetStatuses)
+
+	return result[*form3.Account](values, 0), errorResult(values, ok, 1)
+}
+
+// Watch matches the filter and returns (<-chan form3.AccountEvent, error), a chan form3.AccountEvent is accepted
+func (m *AccountsAPI) Watch(ctx context.Context, filter *form3.WatchFilter) (<-chan form3.AccountEvent, error) {
+	m.t.Helper()
+	values, ok := m.called("Watch", filter)
+
+	if len(values) > 0 {
+		if events, isChan := values[0].(chan form3.AccountEvent); isChan {
+			return events, errorResult(values, ok, 1)
+		}
+	}
+
+	return result[<-chan form3.AccountEvent](values, 0), errorResult(values, ok, 1)
+}
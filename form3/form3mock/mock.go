@@ -0,0 +1,293 @@
+// Package form3mock provides a programmable form3.AccountsAPI for unit tests.
+//
+// Expectations are declared per method with argument matchers and the values to return:
+//
+//	api := form3mock.NewAccountsAPI(t)
+//	api.On("Get", "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc").Return(account, nil, nil)
+//
+// The arguments matched are the ones of the method without its context and options,
+// e.g. the id for Get and the id and version for Delete. The expectations must be met
+// when the test ends, unless AnyTimes is used.
+package form3mock
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// ErrUnexpectedCall is returned by a call matching no expectation
+var ErrUnexpectedCall = errors.New("form3mock: unexpected call")
+
+// TestingT is the part of *testing.T used by the mock
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...any)
+	Cleanup(func())
+}
+
+// Matcher matches an argument of a call
+type Matcher interface {
+	Match(arg any) bool
+	String() string
+}
+
+type anyMatcher struct{}
+
+func (anyMatcher) Match(arg any) bool { return true }
+func (anyMatcher) String() string     { return "any" }
+
+// Any matches every argument
+func Any() Matcher {
+	return anyMatcher{}
+}
+
+type eqMatcher struct {
+	value any
+}
+
+func (m eqMatcher) Match(arg any) bool { return reflect.DeepEqual(m.value, arg) }
+func (m eqMatcher) String() string     { return fmt.Sprintf("%#v", m.value) }
+
+// Eq matches the arguments deeply equal to value. Values given to On are matched with Eq
+func Eq(value any) Matcher {
+	return eqMatcher{value: value}
+}
+
+type funcMatcher[T any] struct {
+	description string
+	fn          func(T) bool
+}
+
+func (m funcMatcher[T]) Match(arg any) bool {
+	typed, ok := arg.(T)
+	return ok && m.fn(typed)
+}
+
+func (m funcMatcher[T]) String() string { return m.description }
+
+// Func matches the arguments of type T accepted by fn, description names it in failures
+func Func[T any](description string, fn func(T) bool) Matcher {
+	return funcMatcher[T]{description: description, fn: fn}
+}
+
+// Call is a call received by the mock
+type Call struct {
+	Method string
+	Args   []any
+}
+
+func (c Call) String() string {
+	args := make([]string, len(c.Args))
+	for i, arg := range c.Args {
+		args[i] = fmt.Sprintf("%#v", arg)
+	}
+
+	return fmt.Sprintf("%s(%s)", c.Method, strings.Join(args, ", "))
+}
+
+// Expectation is a call the mock expects and what it returns
+type Expectation struct {
+	method   string
+	matchers []Matcher
+	returns  []any
+	run      func(args []any)
+	min      int
+	max      int // -1 when unlimited
+	calls    int
+	after    []*Expectation
+}
+
+// Return sets the values returned by the call, in the order of the results of the method
+func (e *Expectation) Return(values ...any) *Expectation {
+	e.returns = values
+	return e
+}
+
+// Run sets a function called with the arguments of each matching call, before it returns
+func (e *Expectation) Run(fn func(args []any)) *Expectation {
+	e.run = fn
+	return e
+}
+
+// Times sets how many calls are expected, once by default
+func (e *Expectation) Times(n int) *Expectation {
+	e.min, e.max = n, n
+	return e
+}
+
+// AnyTimes allows any number of calls, none included
+func (e *Expectation) AnyTimes() *Expectation {
+	e.min, e.max = 0, -1
+	return e
+}
+
+// After makes the expectation match only once the expectations given are met
+func (e *Expectation) After(expectations ...*Expectation) *Expectation {
+	e.after = append(e.after, expectations...)
+	return e
+}
+
+func (e *Expectation) String() string {
+	matchers := make([]string, len(e.matchers))
+	for i, matcher := range e.matchers {
+		matchers[i] = matcher.String()
+	}
+
+	return fmt.Sprintf("%s(%s)", e.method, strings.Join(matchers, ", "))
+}
+
+func (e *Expectation) satisfied() bool {
+	return e.calls >= e.min
+}
+
+func (e *Expectation) exhausted() bool {
+	return e.max >= 0 && e.calls >= e.max
+}
+
+func (e *Expectation) matches(call Call) bool {
+	if e.method != call.Method || len(e.matchers) != len(call.Args) {
+		return false
+	}
+	for i, matcher := range e.matchers {
+		if !matcher.Match(call.Args[i]) {
+			return false
+		}
+	}
+	for _, previous := range e.after {
+		if !previous.satisfied() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// InOrder makes each expectation match only after the previous one is met
+func InOrder(expectations ...*Expectation) {
+	for i := 1; i < len(expectations); i++ {
+		expectations[i].After(expectations[i-1])
+	}
+}
+
+// mock holds the expectations and records the calls, it is embedded by the mocks of the package
+type mock struct {
+	t TestingT
+
+	mu           sync.Mutex
+	expectations []*Expectation
+	calls        []Call
+}
+
+// On adds an expectation for a call of method with arguments matching args,
+// which are Matcher or values matched with Eq
+func (m *mock) On(method string, args ...any) *Expectation {
+	matchers := make([]Matcher, len(args))
+	for i, arg := range args {
+		if matcher, ok := arg.(Matcher); ok {
+			matchers[i] = matcher
+			continue
+		}
+		matchers[i] = Eq(arg)
+	}
+
+	expectation := &Expectation{method: method, matchers: matchers, min: 1, max: 1}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.expectations = append(m.expectations, expectation)
+
+	return expectation
+}
+
+// Calls returns the calls received, in order
+func (m *mock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]Call(nil), m.calls...)
+}
+
+// CallsTo returns the calls of a method received, in order
+func (m *mock) CallsTo(method string) []Call {
+	var calls []Call
+	for _, call := range m.Calls() {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+
+	return calls
+}
+
+// AssertExpectations reports the expectations not met, it is called when the test ends
+func (m *mock) AssertExpectations() bool {
+	m.t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	met := true
+	for _, expectation := range m.expectations {
+		if !expectation.satisfied() {
+			m.t.Errorf("form3mock: expected call %s %d time(s), got %d", expectation, expectation.min, expectation.calls)
+			met = false
+		}
+	}
+
+	return met
+}
+
+// called records the call and returns the values of the expectation it matches, nil if there is none
+func (m *mock) called(method string, args ...any) ([]any, bool) {
+	m.t.Helper()
+	call := Call{Method: method, Args: args}
+
+	m.mu.Lock()
+	m.calls = append(m.calls, call)
+	var matched *Expectation
+	for _, expectation := range m.expectations {
+		if !expectation.exhausted() && expectation.matches(call) {
+			matched = expectation
+			break
+		}
+	}
+	if matched == nil {
+		m.mu.Unlock()
+		m.t.Errorf("form3mock: unexpected call %s", call)
+		return nil, false
+	}
+	matched.calls++
+	m.mu.Unlock()
+
+	if matched.run != nil {
+		matched.run(args)
+	}
+
+	return matched.returns, true
+}
+
+// result returns the value at index of the values returned by an expectation, the zero T if not set
+func result[T any](values []any, index int) T {
+	var zero T
+	if index >= len(values) || values[index] == nil {
+		return zero
+	}
+
+	typed, ok := values[index].(T)
+	if !ok {
+		panic(fmt.Sprintf("form3mock: return value %d is %T, not %T", index, values[index], zero))
+	}
+
+	return typed
+}
+
+// errorResult returns the error of the values of an expectation, ErrUnexpectedCall if there was none
+func errorResult(values []any, ok bool, index int) error {
+	if !ok {
+		return ErrUnexpectedCall
+	}
+
+	return result[error](values, index)
+}
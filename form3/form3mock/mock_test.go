@@ -0,0 +1,125 @@
+package form3mock
+
+import (
+	"context"
+	"errors"
+	"form3-interview-accountapi/form3"
+	"form3-interview-accountapi/form3/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAccountsAPI_expectations(t *testing.T) {
+	recorder := &testsupport.RecordingT{}
+	api := NewAccountsAPI(recorder)
+	account := &form3.Account{ID: "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", Version: 1}
+	notFound := errors.New("record does not exist")
+
+	api.On("Get", "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc").Return(account, nil, nil)
+	api.On("Get", Any()).Return(nil, nil, notFound).AnyTimes()
+	api.On("Delete", Any(), Func("positive version", func(version int) bool { return version > 0 })).Return(nil, nil).Times(2)
+
+	got, _, errGet := api.Get(context.Background(), "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+	_, _, errMissing := api.Get(context.Background(), "b7a1c6f2-1e2c-4bde-8d1b-3c3f1f0e9a11")
+	_, _, errAgain := api.Get(context.Background(), "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+	_, errDelete := api.Delete(context.Background(), "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", 1)
+	recorder.End()
+
+	assert.Nil(t, errGet, "Get error should be nil")
+	assert.Same(t, account, got, "account incorrect")
+	assert.Equal(t, notFound, errMissing, "missing Get error incorrect")
+	assert.Equal(t, notFound, errAgain, "exhausted expectation should not match")
+	assert.Nil(t, errDelete, "Delete error should be nil")
+	assert.Equal(t, []string{`form3mock: expected call Delete(any, positive version) 2 time(s), got 1`}, recorder.Errors, "errors incorrect")
+}
+
+func TestAccountsAPI_unexpectedCall(t *testing.T) {
+	recorder := &testsupport.RecordingT{}
+	api := NewAccountsAPI(recorder)
+	api.On("Delete", "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", 1).Return(nil, nil)
+
+	_, err := api.Delete(context.Background(), "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", 0)
+
+	assert.ErrorIs(t, err, ErrUnexpectedCall, "Delete error incorrect")
+	assert.Equal(t, []string{`form3mock: unexpected call Delete("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", 0)`}, recorder.Errors, "errors incorrect")
+}
+
+func TestAccountsAPI_calls(t *testing.T) {
+	api := NewAccountsAPI(t)
+	api.On("Create", Any()).Return(&form3.Account{}, nil, nil).AnyTimes()
+	api.On("Confirm", Any()).Return(&form3.Account{}, nil, nil)
+	account := &form3.Account{ID: "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc"}
+
+	_, _, _ = api.Create(context.Background(), account)
+	_, _, _ = api.Confirm(context.Background(), account.ID)
+	_, _, _ = api.Create(context.Background(), account)
+
+	assert.Equal(t, []Call{
+		{Method: "Create", Args: []any{account}},
+		{Method: "Confirm", Args: []any{account.ID}},
+		{Method: "Create", Args: []any{account}},
+	}, api.Calls(), "calls incorrect")
+	assert.Len(t, api.CallsTo("Create"), 2, "Create calls incorrect")
+}
+
+func TestInOrder(t *testing.T) {
+	recorder := &testsupport.RecordingT{}
+	api := NewAccountsAPI(recorder)
+	InOrder(
+		api.On("Get", Any()).Return(&form3.Account{Version: 3}, nil, nil),
+		api.On("Delete", Any(), 3).Return(nil, nil),
+	)
+
+	_, errEarly := api.Delete(context.Background(), "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", 3)
+	_, _, _ = api.Get(context.Background(), "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+	_, errDelete := api.Delete(context.Background(), "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", 3)
+	recorder.End()
+
+	assert.ErrorIs(t, errEarly, ErrUnexpectedCall, "Delete before Get should be unexpected")
+	assert.Nil(t, errDelete, "Delete error should be nil")
+	assert.Len(t, recorder.Errors, 1, "errors incorrect")
+}
+
+func TestAccountsAPI_run(t *testing.T) {
+	api := NewAccountsAPI(t)
+	var updated *form3.AccountAttributesPatch
+	api.On("Update", "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", 1, Any()).
+		Run(func(args []any) { updated = args[2].(*form3.AccountAttributesPatch) }).
+		Return(&form3.Account{Version: 2}, nil, nil)
+	patch := &form3.AccountAttributesPatch{Bic: form3.Some("NWBKGB22")}
+
+	account, _, err := api.Update(context.Background(), "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", 1, patch)
+
+	assert.Nil(t, err, "Update error should be nil")
+	assert.Equal(t, 2, account.Version, "Version incorrect")
+	assert.Same(t, patch, updated, "patch incorrect")
+}
+
+func TestAccountsAPI_ListEach(t *testing.T) {
+	api := NewAccountsAPI(t)
+	api.On("ListEach", Any()).Return([]form3.Account{{ID: "a"}, {ID: "b"}, {ID: "c"}}, nil)
+
+	var ids []string
+	err := api.ListEach(context.Background(), nil, func(account *form3.Account) error {
+		ids = append(ids, account.ID)
+		if account.ID == "b" {
+			return form3.ErrStopListing
+		}
+		return nil
+	})
+
+	assert.Nil(t, err, "ListEach error should be nil")
+	assert.Equal(t, []string{"a", "b"}, ids, "ids incorrect")
+}
+
+func TestAccountsAPI_Watch(t *testing.T) {
+	api := NewAccountsAPI(t)
+	events := make(chan form3.AccountEvent, 1)
+	events <- form3.AccountEvent{Type: form3.AccountEventCreated}
+	api.On("Watch", Any()).Return(events, nil)
+
+	received, err := api.Watch(context.Background(), nil)
+
+	assert.Nil(t, err, "Watch error should be nil")
+	assert.Equal(t, form3.AccountEventCreated, (<-received).Type, "event incorrect")
+}
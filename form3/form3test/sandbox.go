@@ -0,0 +1,368 @@
+// Package form3test provides helpers for the tests running against an account API.
+package form3test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"form3-interview-accountapi/form3"
+	"github.com/google/uuid"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	organisationIDFilter  = "organisation_id"
+	defaultCleanupTimeout = 30 * time.Second
+)
+
+// ErrOutsideSandbox is returned for the accounts a Sandbox did not create, which it does not touch
+var ErrOutsideSandbox = errors.New("form3test: account not created through the sandbox")
+
+// TestingT is the part of *testing.T used by the sandbox
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...any)
+	Cleanup(func())
+}
+
+// Sandbox is a form3.AccountsAPI that deletes the accounts created through it when the test ends.
+// It isolates the test with an organisation ID of its own, given to the accounts created and used to
+// filter List, ListEach and Watch, on the API and again on the accounts returned in case the API ignores
+// the filter. The other methods return ErrOutsideSandbox for the accounts it did not create, and Get for
+// the accounts of another organisation.
+type Sandbox struct {
+	form3.AccountsAPI
+
+	t              TestingT
+	organisationID string
+
+	mu      sync.Mutex
+	created []string
+	tracked map[string]bool
+	leaked  []string
+}
+
+var _ form3.AccountsAPI = (*Sandbox)(nil)
+
+// NewSandbox returns a Sandbox instance wrapping api, cleaned up when the test of t ends
+func NewSandbox(t TestingT, api form3.AccountsAPI) *Sandbox {
+	s := &Sandbox{
+		AccountsAPI:    api,
+		t:              t,
+		organisationID: uuid.New().String(),
+		tracked:        map[string]bool{},
+	}
+	t.Cleanup(s.cleanup)
+
+	return s
+}
+
+// OrganisationID returns the organisation ID of the test
+func (s *Sandbox) OrganisationID() string {
+	return s.organisationID
+}
+
+// Tracked returns the IDs of the accounts created and not deleted yet, in creation order
+func (s *Sandbox) Tracked() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var ids []string
+	for _, id := range s.created {
+		if s.tracked[id] {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+// Leaked returns the IDs of the accounts the cleanup could not delete
+func (s *Sandbox) Leaked() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.leaked...)
+}
+
+// Create creates the account with the organisation ID of the test, and a new ID when it has none, and tracks it.
+// The account is tracked before it is sent, a creation failing on the client may have succeeded on the API,
+// and only forgotten when the API rejects it. The account given is not modified.
+func (s *Sandbox) Create(ctx context.Context, data *form3.Account, opts ...form3.CallOption) (*form3.Account, *form3.RestClientResponse, error) {
+	if data == nil {
+		return s.AccountsAPI.Create(ctx, data, opts...)
+	}
+
+	sent := *data
+	sent.OrganisationID = s.organisationID
+	if sent.ID == "" {
+		sent.ID = uuid.New().String()
+	}
+	added := s.track(sent.ID)
+
+	account, resp, err := s.AccountsAPI.Create(ctx, &sent, opts...)
+	switch {
+	case err == nil && account != nil:
+		s.track(account.ID)
+	case err != nil && added && rejected(resp):
+		s.forget(sent.ID)
+	}
+
+	return account, resp, err
+}
+
+func (s *Sandbox) Get(ctx context.Context, id string, opts ...form3.CallOption) (*form3.Account, *form3.RestClientResponse, error) {
+	account, resp, err := s.AccountsAPI.Get(ctx, id, opts...)
+	if err == nil && account != nil && account.OrganisationID != s.organisationID {
+		return nil, resp, fmt.Errorf("%w: %s", ErrOutsideSandbox, id)
+	}
+
+	return account, resp, err
+}
+
+func (s *Sandbox) List(ctx context.Context, listOpts *form3.ListOptions, opts ...form3.CallOption) ([]form3.Account, *form3.RestClientResponse, error) {
+	accounts, resp, err := s.AccountsAPI.List(ctx, s.scope(listOpts), opts...)
+
+	var scoped []form3.Account
+	for _, account := range accounts {
+		if account.OrganisationID == s.organisationID {
+			scoped = append(scoped, account)
+		}
+	}
+
+	return scoped, resp, err
+}
+
+func (s *Sandbox) ListEach(ctx context.Context, listOpts *form3.ListOptions, fn func(account *form3.Account) error, opts ...form3.CallOption) error {
+	return s.AccountsAPI.ListEach(ctx, s.scope(listOpts), func(account *form3.Account) error {
+		if account.OrganisationID != s.organisationID {
+			return nil
+		}
+		return fn(account)
+	}, opts...)
+}
+
+func (s *Sandbox) Update(ctx context.Context, id string, version int, attributes *form3.AccountAttributesPatch, opts ...form3.CallOption) (*form3.Account, *form3.RestClientResponse, error) {
+	if err := s.inScope(id); err != nil {
+		return nil, nil, err
+	}
+
+	return s.AccountsAPI.Update(ctx, id, version, attributes, opts...)
+}
+
+func (s *Sandbox) Delete(ctx context.Context, id string, version int, opts ...form3.CallOption) (*form3.RestClientResponse, error) {
+	if err := s.inScope(id); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.AccountsAPI.Delete(ctx, id, version, opts...)
+	// a missing account is not an error of Delete, it is only deleted on a 2xx response
+	if err == nil && resp != nil && resp.Response != nil && resp.StatusCode < 300 {
+		s.untrack(id)
+	}
+
+	return resp, err
+}
+
+func (s *Sandbox) DeleteLatest(ctx context.Context, id string, opts ...form3.DeleteLatestOption) (*form3.RestClientResponse, error) {
+	if err := s.inScope(id); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.AccountsAPI.DeleteLatest(ctx, id, opts...)
+	if err == nil {
+		s.untrack(id)
+	}
+
+	return resp, err
+}
+
+func (s *Sandbox) Transition(ctx context.Context, id string, to form3.AccountStatus, opts ...form3.CallOption) (*form3.Account, *form3.RestClientResponse, error) {
+	if err := s.inScope(id); err != nil {
+		return nil, nil, err
+	}
+
+	return s.AccountsAPI.Transition(ctx, id, to, opts...)
+}
+
+func (s *Sandbox) Confirm(ctx context.Context, id string, opts ...form3.CallOption) (*form3.Account, *form3.RestClientResponse, error) {
+	if err := s.inScope(id); err != nil {
+		return nil, nil, err
+	}
+
+	return s.AccountsAPI.Confirm(ctx, id, opts...)
+}
+
+func (s *Sandbox) Close(ctx context.Context, id string, opts ...form3.CallOption) (*form3.Account, *form3.RestClientResponse, error) {
+	if err := s.inScope(id); err != nil {
+		return nil, nil, err
+	}
+
+	return s.AccountsAPI.Close(ctx, id, opts...)
+}
+
+func (s *Sandbox) WaitForStatus(ctx context.Context, id string, opts *form3.WaitOptions, targetStatuses ...form3.AccountStatus) (*form3.Account, error) {
+	if err := s.inScope(id); err != nil {
+		return nil, err
+	}
+
+	return s.AccountsAPI.WaitForStatus(ctx, id, opts, targetStatuses...)
+}
+
+func (s *Sandbox) Watch(ctx context.Context, filter *form3.WatchFilter) (<-chan form3.AccountEvent, error) {
+	scoped := form3.WatchFilter{}
+	if filter != nil {
+		scoped = *filter
+	}
+	scoped.Filter = s.scopeFilter(scoped.Filter)
+
+	events, err := s.AccountsAPI.Watch(ctx, &scoped)
+	if err != nil {
+		return nil, err
+	}
+
+	scopedEvents := make(chan form3.AccountEvent)
+	go func() {
+		defer close(scopedEvents)
+
+		// deleted events only hold the id, they are passed for the accounts of the test seen before
+		watched := map[string]bool{}
+		for event := range events {
+			if event.Account != nil {
+				id := event.Account.ID
+				switch {
+				case event.Type == form3.AccountEventDeleted && !watched[id]:
+					continue
+				case event.Type == form3.AccountEventDeleted:
+					delete(watched, id)
+				case event.Account.OrganisationID != s.organisationID:
+					continue
+				default:
+					watched[id] = true
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case scopedEvents <- event:
+			}
+		}
+	}()
+
+	return scopedEvents, nil
+}
+
+// scope sets the organisation of the test in the filter of listOpts
+func (s *Sandbox) scope(listOpts *form3.ListOptions) *form3.ListOptions {
+	scoped := form3.ListOptions{}
+	if listOpts != nil {
+		scoped = *listOpts
+	}
+	scoped.Filter = s.scopeFilter(scoped.Filter)
+
+	return &scoped
+}
+
+// scopeFilter returns a copy of filter with the organisation of the test
+func (s *Sandbox) scopeFilter(filter map[string]string) map[string]string {
+	scoped := map[string]string{}
+	for key, value := range filter {
+		scoped[key] = value
+	}
+	scoped[organisationIDFilter] = s.organisationID
+
+	return scoped
+}
+
+// rejected reports whether the API answered with a client error, so the request changed nothing
+func rejected(resp *form3.RestClientResponse) bool {
+	return resp != nil && resp.Response != nil && resp.StatusCode >= 400 && resp.StatusCode < 500
+}
+
+// inScope returns ErrOutsideSandbox unless the account was created through the sandbox, deleted or not
+func (s *Sandbox) inScope(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.tracked[id]; !ok {
+		return fmt.Errorf("%w: %s", ErrOutsideSandbox, id)
+	}
+
+	return nil
+}
+
+// track tracks the account, reporting whether it was not known yet
+func (s *Sandbox) track(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, known := s.tracked[id]
+	if !known {
+		s.created = append(s.created, id)
+	}
+	s.tracked[id] = true
+
+	return !known
+}
+
+// forget removes an account the API refused to create, so it is neither deleted nor in scope
+func (s *Sandbox) forget(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.tracked, id)
+	for i, created := range s.created {
+		if created == id {
+			s.created = append(s.created[:i], s.created[i+1:]...)
+			break
+		}
+	}
+}
+
+func (s *Sandbox) untrack(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.tracked[id]; ok {
+		s.tracked[id] = false
+	}
+}
+
+// cleanup deletes the tracked accounts, newest first, at their current version, and reports the ones left
+func (s *Sandbox) cleanup() {
+	s.t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultCleanupTimeout)
+	defer cancel()
+
+	ids := s.Tracked()
+	failures := map[string]error{}
+	for i := len(ids) - 1; i >= 0; i-- {
+		if _, err := s.DeleteLatest(ctx, ids[i], form3.WithNotFoundAsSuccess()); err != nil {
+			failures[ids[i]] = err
+		}
+	}
+	if len(failures) == 0 {
+		return
+	}
+
+	leaked := make([]string, 0, len(failures))
+	for id := range failures {
+		leaked = append(leaked, id)
+	}
+	sort.Strings(leaked)
+
+	s.mu.Lock()
+	s.leaked = leaked
+	s.mu.Unlock()
+
+	report := make([]string, len(leaked))
+	for i, id := range leaked {
+		report[i] = fmt.Sprintf("%s: %v", id, failures[id])
+	}
+	s.t.Errorf("form3test: %d account(s) leaked:\n%s", len(leaked), strings.Join(report, "\n"))
+}
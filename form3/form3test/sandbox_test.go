@@ -0,0 +1,192 @@
+package form3test
+
+import (
+	"context"
+	"errors"
+	"form3-interview-accountapi/form3"
+	"form3-interview-accountapi/form3/form3mock"
+	"form3-interview-accountapi/form3/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestSandbox_cleanup(t *testing.T) {
+	api := form3mock.NewAccountsAPI(t)
+	api.On("Create", form3mock.Any()).Return(&form3.Account{ID: "first"}, nil, nil)
+	api.On("Create", form3mock.Any()).Return(&form3.Account{ID: "second"}, nil, nil)
+	api.On("Create", form3mock.Any()).Return(&form3.Account{ID: "third"}, nil, nil)
+	api.On("Delete", "second", 0).Return(&form3.RestClientResponse{Response: &http.Response{StatusCode: http.StatusNoContent}}, nil)
+	form3mock.InOrder(
+		api.On("DeleteLatest", "third").Return(nil, nil),
+		api.On("DeleteLatest", "first").Return(nil, nil),
+	)
+	recorder := &testsupport.RecordingT{}
+	sandbox := NewSandbox(recorder, api)
+	ctx := context.Background()
+
+	_, _, _ = sandbox.Create(ctx, &form3.Account{ID: "first"})
+	_, _, _ = sandbox.Create(ctx, &form3.Account{ID: "second"})
+	_, _, _ = sandbox.Create(ctx, &form3.Account{ID: "third"})
+	_, _ = sandbox.Delete(ctx, "second", 0)
+
+	assert.Equal(t, []string{"first", "third"}, sandbox.Tracked(), "tracked accounts incorrect")
+	recorder.End()
+	assert.Empty(t, recorder.Errors, "errors should be empty")
+	assert.Empty(t, sandbox.Tracked(), "tracked accounts should be deleted")
+	assert.Empty(t, sandbox.Leaked(), "no account should leak")
+}
+
+func TestSandbox_leaks(t *testing.T) {
+	api := form3mock.NewAccountsAPI(t)
+	api.On("Create", form3mock.Any()).Return(&form3.Account{ID: "first"}, nil, nil)
+	api.On("DeleteLatest", "first").Return(nil, errors.New("invalid version")).Times(1)
+	recorder := &testsupport.RecordingT{}
+	sandbox := NewSandbox(recorder, api)
+
+	_, _, _ = sandbox.Create(context.Background(), &form3.Account{ID: "first"})
+	recorder.End()
+
+	assert.Equal(t, []string{"first"}, sandbox.Leaked(), "leaked accounts incorrect")
+	assert.Equal(t, []string{"form3test: 1 account(s) leaked:\nfirst: invalid version"}, recorder.Errors, "errors incorrect")
+}
+
+func TestSandbox_Create_organisationID(t *testing.T) {
+	api := form3mock.NewAccountsAPI(t)
+	var sent []*form3.Account
+	api.On("Create", form3mock.Any()).
+		Run(func(args []any) { sent = append(sent, args[0].(*form3.Account)) }).
+		Return(nil, nil, errors.New("validation failure")).
+		Times(2)
+	sandbox := NewSandbox(&testsupport.RecordingT{}, api)
+	withoutOrganisation := &form3.Account{ID: "first"}
+	withOrganisation := &form3.Account{ID: "second", OrganisationID: "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c"}
+
+	_, _, _ = sandbox.Create(context.Background(), withoutOrganisation)
+	_, _, _ = sandbox.Create(context.Background(), withOrganisation)
+
+	assert.Equal(t, sandbox.OrganisationID(), sent[0].OrganisationID, "organisation of the sandbox should be set")
+	assert.Equal(t, sandbox.OrganisationID(), sent[1].OrganisationID, "organisation of the sandbox should replace the account one")
+	assert.Empty(t, withoutOrganisation.OrganisationID, "account given should not be modified")
+	assert.Equal(t, "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c", withOrganisation.OrganisationID, "account given should not be modified")
+	assert.Equal(t, []string{"first", "second"}, sandbox.Tracked(), "creations failing without response should be tracked")
+	assert.NotEqual(t, sandbox.OrganisationID(), NewSandbox(&testsupport.RecordingT{}, api).OrganisationID(), "sandboxes should have their own organisation")
+}
+
+func TestSandbox_Create_failures(t *testing.T) {
+	api := form3mock.NewAccountsAPI(t)
+	api.On("Create", form3mock.Any()).Return(nil, nil, context.DeadlineExceeded)
+	api.On("Create", form3mock.Any()).Return(nil, &form3.RestClientResponse{Response: &http.Response{StatusCode: http.StatusConflict}},
+		errors.New("Account cannot be created as it violates a duplicate constraint"))
+	api.On("DeleteLatest", "timed-out").Return(nil, nil).Times(1)
+	recorder := &testsupport.RecordingT{}
+	sandbox := NewSandbox(recorder, api)
+
+	_, _, _ = sandbox.Create(context.Background(), &form3.Account{ID: "timed-out"})
+	_, _, _ = sandbox.Create(context.Background(), &form3.Account{ID: "duplicate"})
+
+	assert.Equal(t, []string{"timed-out"}, sandbox.Tracked(), "only the creation rejected by the API should be forgotten")
+	_, err := sandbox.Delete(context.Background(), "duplicate", 0)
+	assert.ErrorIs(t, err, ErrOutsideSandbox, "rejected account should be outside the sandbox")
+	recorder.End()
+	assert.Empty(t, recorder.Errors, "errors should be empty")
+}
+
+func TestSandbox_Create_newID(t *testing.T) {
+	api := form3mock.NewAccountsAPI(t)
+	var sent *form3.Account
+	api.On("Create", form3mock.Any()).
+		Run(func(args []any) { sent = args[0].(*form3.Account) }).
+		Return(nil, nil, context.DeadlineExceeded)
+	sandbox := NewSandbox(&testsupport.RecordingT{}, api)
+
+	_, _, _ = sandbox.Create(context.Background(), &form3.Account{})
+
+	assert.NotEmpty(t, sent.ID, "account without id should be given one")
+	assert.Equal(t, []string{sent.ID}, sandbox.Tracked(), "tracked accounts incorrect")
+}
+
+func TestSandbox_filterIgnoredByAPI(t *testing.T) {
+	other := form3.Account{ID: "0f6d1a3e-6a8c-4a43-a8a4-1b6a3e2f1c01", OrganisationID: "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c",
+		Type: form3.AcctTypeAccounts, Attributes: &form3.AccountAttributes{Country: form3.CountryCodeBelgium}}
+	api := testsupport.NewFakeAPI(t, testsupport.FakeAPIOptions{}, other)
+	sandbox := NewSandbox(t, api.Service())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	created, _, err := sandbox.Create(ctx, &form3.Account{ID: "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", Type: form3.AcctTypeAccounts,
+		Attributes: &form3.AccountAttributes{Country: form3.CountryCodeBelgium}})
+	assert.Nil(t, err, "Create error should be nil")
+
+	listed, _, err := sandbox.List(ctx, nil)
+	assert.Nil(t, err, "List error should be nil")
+	assert.Equal(t, []form3.Account{*created}, listed, "List should only return the accounts of the sandbox")
+
+	var each []string
+	err = sandbox.ListEach(ctx, nil, func(account *form3.Account) error {
+		each = append(each, account.ID)
+		return nil
+	})
+	assert.Nil(t, err, "ListEach error should be nil")
+	assert.Equal(t, []string{created.ID}, each, "ListEach should only pass the accounts of the sandbox")
+
+	events, err := sandbox.Watch(ctx, &form3.WatchFilter{Interval: time.Hour})
+	assert.Nil(t, err, "Watch error should be nil")
+	select {
+	case event := <-events:
+		assert.Equal(t, form3.AccountEventCreated, event.Type, "event type incorrect")
+		assert.Equal(t, created.ID, event.Account.ID, "Watch should only emit the accounts of the sandbox")
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for the watch event")
+	}
+}
+
+func TestSandbox_List_scoped(t *testing.T) {
+	api := form3mock.NewAccountsAPI(t)
+	sandbox := NewSandbox(&testsupport.RecordingT{}, api)
+	api.On("List", &form3.ListOptions{PageSize: 10, Filter: map[string]string{
+		"organisation_id": sandbox.OrganisationID(),
+		"country":         "GB",
+	}}).Return(nil, nil, nil)
+	api.On("ListEach", &form3.ListOptions{Filter: map[string]string{"organisation_id": sandbox.OrganisationID()}}).Return(nil, nil)
+	listOpts := &form3.ListOptions{PageSize: 10, Filter: map[string]string{"country": "GB"}}
+
+	_, _, errList := sandbox.List(context.Background(), listOpts)
+	errListEach := sandbox.ListEach(context.Background(), &form3.ListOptions{Filter: map[string]string{"organisation_id": "other"}},
+		func(account *form3.Account) error { return nil })
+
+	assert.Nil(t, errList, "List error should be nil")
+	assert.Nil(t, errListEach, "ListEach error should be nil")
+	assert.Equal(t, map[string]string{"country": "GB"}, listOpts.Filter, "list options given should not be modified")
+}
+
+func TestSandbox_Watch_scoped(t *testing.T) {
+	api := form3mock.NewAccountsAPI(t)
+	sandbox := NewSandbox(&testsupport.RecordingT{}, api)
+	api.On("Watch", &form3.WatchFilter{PageSize: 10, Filter: map[string]string{"organisation_id": sandbox.OrganisationID()}}).
+		Return(make(chan form3.AccountEvent), nil)
+
+	_, err := sandbox.Watch(context.Background(), &form3.WatchFilter{PageSize: 10, Filter: map[string]string{"organisation_id": "other"}})
+
+	assert.Nil(t, err, "Watch error should be nil")
+}
+
+func TestSandbox_outsideSandbox(t *testing.T) {
+	api := form3mock.NewAccountsAPI(t)
+	sandbox := NewSandbox(&testsupport.RecordingT{}, api)
+	api.On("Get", "other").Return(&form3.Account{ID: "other", OrganisationID: "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c"}, nil, nil)
+	ctx := context.Background()
+
+	_, _, errGet := sandbox.Get(ctx, "other")
+	_, _, errUpdate := sandbox.Update(ctx, "other", 0, &form3.AccountAttributesPatch{})
+	_, errDelete := sandbox.Delete(ctx, "other", 0)
+	_, errDeleteLatest := sandbox.DeleteLatest(ctx, "other")
+	_, _, errTransition := sandbox.Transition(ctx, "other", form3.AcctStatusConfirmed)
+	_, _, errConfirm := sandbox.Confirm(ctx, "other")
+	_, _, errClose := sandbox.Close(ctx, "other")
+	_, errWait := sandbox.WaitForStatus(ctx, "other", nil, form3.AcctStatusConfirmed)
+
+	for _, err := range []error{errGet, errUpdate, errDelete, errDeleteLatest, errTransition, errConfirm, errClose, errWait} {
+		assert.ErrorIs(t, err, ErrOutsideSandbox, "error incorrect")
+	}
+}
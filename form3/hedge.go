@@ -0,0 +1,247 @@
+package form3
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultHedgeBudget = 0.1
+	// hedgeLatencySamples is the number of recent latencies the learned delay is computed from
+	hedgeLatencySamples = 100
+	// hedgeMinSamples is the number of latencies needed before the learned delay is used
+	hedgeMinSamples = 20
+)
+
+// HedgePolicy sets when a second identical request is sent for a slow GET or HEAD request.
+// The first successful response is used and the other request is cancelled.
+type HedgePolicy struct {
+	// Delay is the wait for a response before hedging. When Percentile is set it is only used
+	// until enough latencies have been observed
+	Delay time.Duration
+	// Percentile learns the delay from the latencies of recent requests, e.g. 0.95 hedges the requests
+	// slower than 95% of them
+	Percentile float64
+	// Budget caps the hedged requests to a fraction of the requests, 0.1 by default
+	Budget float64
+}
+
+// HedgingStats counts the requests handled by the hedging of a RestClient
+type HedgingStats struct {
+	// Requests is the number of requests that could be hedged
+	Requests int64
+	// Hedged is the number of them that sent a second request
+	Hedged int64
+	// HedgeWins is the number of hedged requests answered first by the second request
+	HedgeWins int64
+}
+
+type hedger struct {
+	policy HedgePolicy
+
+	mu        sync.Mutex
+	latencies []time.Duration
+	next      int
+	stats     HedgingStats
+}
+
+func newHedger(policy *HedgePolicy) *hedger {
+	if policy == nil {
+		return nil
+	}
+
+	h := &hedger{policy: *policy}
+	if h.policy.Budget <= 0 {
+		h.policy.Budget = defaultHedgeBudget
+	}
+
+	return h
+}
+
+// HedgingStats returns the counters of the request hedging, zero when it is disabled
+func (c *RestClient) HedgingStats() HedgingStats {
+	if c.hedger == nil {
+		return HedgingStats{}
+	}
+
+	c.hedger.mu.Lock()
+	defer c.hedger.mu.Unlock()
+
+	return c.hedger.stats
+}
+
+// delay returns the wait before hedging, false when the request must not be hedged
+func (h *hedger) delay() (time.Duration, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.stats.Requests++
+	if h.policy.Percentile > 0 && len(h.latencies) >= hedgeMinSamples {
+		sorted := append([]time.Duration(nil), h.latencies...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		index := int(float64(len(sorted)) * h.policy.Percentile)
+		if index >= len(sorted) {
+			index = len(sorted) - 1
+		}
+		return sorted[index], true
+	}
+
+	return h.policy.Delay, h.policy.Delay > 0
+}
+
+// allowHedge reports whether the budget allows another hedged request, counting it if so
+func (h *hedger) allowHedge() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if float64(h.stats.Hedged+1) > h.policy.Budget*float64(h.stats.Requests) {
+		return false
+	}
+	h.stats.Hedged++
+
+	return true
+}
+
+func (h *hedger) observe(latency time.Duration, hedgeWon bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if hedgeWon {
+		h.stats.HedgeWins++
+	}
+	if len(h.latencies) < hedgeLatencySamples {
+		h.latencies = append(h.latencies, latency)
+		return
+	}
+	h.latencies[h.next] = latency
+	h.next = (h.next + 1) % hedgeLatencySamples
+}
+
+// roundTrip sends the request, hedging it when the client is configured to and the request is a read
+func (c *RestClient) roundTrip(ctx context.Context, req *http.Request, policy *RetryPolicy) (*http.Response, error) {
+	if c.hedger == nil || (req.Method != http.MethodGet && req.Method != http.MethodHead) {
+		return c.send(ctx, req, policy)
+	}
+
+	delay, ok := c.hedger.delay()
+	if !ok {
+		start := time.Now()
+		resp, err := c.send(ctx, req, policy)
+		if err == nil {
+			c.hedger.observe(time.Since(start), false)
+		}
+		return resp, err
+	}
+
+	return c.sendHedged(ctx, req, policy, delay)
+}
+
+type hedgeResult struct {
+	resp   *http.Response
+	err    error
+	hedge  bool
+	index  int
+	cancel context.CancelFunc
+}
+
+// succeeded reports whether the response can be used, server errors are left for the other request
+func (r hedgeResult) succeeded() bool {
+	return r.err == nil && r.resp.StatusCode < http.StatusInternalServerError
+}
+
+// release closes the response of a request not used and cancels it
+func (r hedgeResult) release() {
+	if r.resp != nil {
+		_, _ = io.Copy(io.Discard, r.resp.Body)
+		r.resp.Body.Close()
+	}
+	r.cancel()
+}
+
+// sendHedged sends the request and a second one if no response arrives within delay, the budget allowing.
+// The first successful response is returned and the other request cancelled. The request of the returned
+// response is cancelled once its body is closed. The latency observed is measured from the first request,
+// the one a hedge winning was also waiting for.
+func (c *RestClient) sendHedged(ctx context.Context, req *http.Request, policy *RetryPolicy, delay time.Duration) (*http.Response, error) {
+	results := make(chan hedgeResult, 2)
+	var cancels []context.CancelFunc
+	launch := func(hedge bool) {
+		attemptCtx, cancel := context.WithCancel(ctx)
+		index := len(cancels)
+		cancels = append(cancels, cancel)
+		go func() {
+			resp, err := c.send(attemptCtx, req, policy)
+			results <- hedgeResult{resp: resp, err: err, hedge: hedge, index: index, cancel: cancel}
+		}()
+	}
+
+	start := time.Now()
+	launch(false)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	var failed *hedgeResult
+	for received := 0; received < len(cancels); {
+		select {
+		case <-timer.C:
+			if c.hedger.allowHedge() {
+				launch(true)
+			}
+		case result := <-results:
+			received++
+			if !result.succeeded() {
+				if failed != nil {
+					failed.release()
+				}
+				failed = &result
+				continue
+			}
+
+			c.hedger.observe(time.Since(start), result.hedge)
+			if failed != nil {
+				failed.release()
+			}
+			// the request still in flight is cancelled, its result released when it returns
+			if pending := len(cancels) - received; pending > 0 {
+				for i, cancel := range cancels {
+					if i != result.index {
+						cancel()
+					}
+				}
+				go func() {
+					for i := 0; i < pending; i++ {
+						(<-results).release()
+					}
+				}()
+			}
+			result.resp.Body = &cancelOnClose{ReadCloser: result.resp.Body, cancel: result.cancel}
+			return result.resp, nil
+		}
+	}
+
+	// no request succeeded, the last failure is returned
+	if failed.resp == nil {
+		failed.cancel()
+		return nil, failed.err
+	}
+	failed.resp.Body = &cancelOnClose{ReadCloser: failed.resp.Body, cancel: failed.cancel}
+
+	return failed.resp, nil
+}
+
+// cancelOnClose cancels the request of a response body once it is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+
+	return err
+}
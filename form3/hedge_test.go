@@ -0,0 +1,148 @@
+package form3
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// slowFirstHttpClient keeps the first request until it is cancelled and answers the others after latency
+type slowFirstHttpClient struct {
+	latency  time.Duration
+	requests atomic.Int64
+	canceled atomic.Int64
+}
+
+func (c *slowFirstHttpClient) Do(req *http.Request) (*http.Response, error) {
+	if c.requests.Add(1) == 1 {
+		<-req.Context().Done()
+		c.canceled.Add(1)
+		return nil, req.Context().Err()
+	}
+
+	select {
+	case <-time.After(c.latency):
+		return mockedResponse(http.StatusOK, `{"data":{"id":"ad27e265-9605-4b4b-a0e5-3003ea9cc4dc","version":1}}`, nil), nil
+	case <-req.Context().Done():
+		c.canceled.Add(1)
+		return nil, req.Context().Err()
+	}
+}
+
+func TestAccountsService_Get_hedged(t *testing.T) {
+	httpClient := &slowFirstHttpClient{}
+	client, _ := NewRestClient(httpClient, NewRestClientParams{
+		BaseUrl: baseFakeUrl,
+		Hedging: &HedgePolicy{Delay: 10 * time.Millisecond, Budget: 1},
+	})
+
+	account, _, err := NewAccountsService(client).Get(context.Background(), "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+
+	assert.Nil(t, err, "Get error should be nil")
+	assert.Equal(t, 1, account.Version, "Version incorrect")
+	assert.Equal(t, HedgingStats{Requests: 1, Hedged: 1, HedgeWins: 1}, client.HedgingStats(), "stats incorrect")
+	assert.Len(t, client.hedger.latencies, 1, "latencies incorrect")
+	assert.GreaterOrEqual(t, client.hedger.latencies[0], 10*time.Millisecond,
+		"latency should be measured from the first request")
+	assert.Eventually(t, func() bool { return httpClient.canceled.Load() == 1 }, time.Second, time.Millisecond,
+		"slow request should be canceled")
+}
+
+func TestAccountsService_Get_notHedgedWhenFast(t *testing.T) {
+	requests := 0
+	mockedHttpClient := mockedHttpClientHandler(func(req *http.Request) (*http.Response, error) {
+		requests++
+		return mockedResponse(http.StatusOK, `{"data":{"id":"ad27e265-9605-4b4b-a0e5-3003ea9cc4dc","version":1}}`, nil), nil
+	})
+	client, _ := NewRestClient(mockedHttpClient, NewRestClientParams{
+		BaseUrl: baseFakeUrl,
+		Hedging: &HedgePolicy{Delay: time.Second, Budget: 1},
+	})
+
+	_, _, err := NewAccountsService(client).Get(context.Background(), "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+
+	assert.Nil(t, err, "Get error should be nil")
+	assert.Equal(t, 1, requests, "requests incorrect")
+	assert.Equal(t, HedgingStats{Requests: 1}, client.HedgingStats(), "stats incorrect")
+}
+
+func TestAccountsService_Get_hedgingBudget(t *testing.T) {
+	mockedHttpClient := mockedHttpClientHandler(func(req *http.Request) (*http.Response, error) {
+		select {
+		case <-time.After(20 * time.Millisecond):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+		return mockedResponse(http.StatusOK, `{"data":{"id":"ad27e265-9605-4b4b-a0e5-3003ea9cc4dc","version":1}}`, nil), nil
+	})
+	client, _ := NewRestClient(mockedHttpClient, NewRestClientParams{
+		BaseUrl: baseFakeUrl,
+		Hedging: &HedgePolicy{Delay: time.Millisecond, Budget: 0.5},
+	})
+	service := NewAccountsService(client)
+
+	for i := 0; i < 4; i++ {
+		_, _, err := service.Get(context.Background(), "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+		assert.Nil(t, err, "Get error should be nil")
+	}
+
+	stats := client.HedgingStats()
+	assert.Equal(t, int64(4), stats.Requests, "Requests incorrect")
+	assert.Equal(t, int64(2), stats.Hedged, "Hedged incorrect")
+}
+
+func TestAccountsService_Get_hedgedServerError(t *testing.T) {
+	var requests atomic.Int64
+	mockedHttpClient := mockedHttpClientHandler(func(req *http.Request) (*http.Response, error) {
+		if requests.Add(1) == 1 {
+			time.Sleep(20 * time.Millisecond)
+			return mockedResponse(http.StatusOK, `{"data":{"id":"ad27e265-9605-4b4b-a0e5-3003ea9cc4dc","version":1}}`, nil), nil
+		}
+		return mockedResponse(http.StatusServiceUnavailable, `{"error_message":"unavailable"}`, nil), nil
+	})
+	client, _ := NewRestClient(mockedHttpClient, NewRestClientParams{
+		BaseUrl: baseFakeUrl,
+		Hedging: &HedgePolicy{Delay: time.Millisecond, Budget: 1},
+	})
+
+	account, _, err := NewAccountsService(client).Get(context.Background(), "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+
+	assert.Nil(t, err, "Get error should be nil")
+	assert.Equal(t, 1, account.Version, "Version incorrect")
+	assert.Equal(t, HedgingStats{Requests: 1, Hedged: 1}, client.HedgingStats(), "stats incorrect")
+}
+
+func TestAccountsService_Delete_notHedged(t *testing.T) {
+	httpClient := &slowFirstHttpClient{}
+	client, _ := NewRestClient(httpClient, NewRestClientParams{
+		BaseUrl: baseFakeUrl,
+		Hedging: &HedgePolicy{Delay: time.Millisecond, Budget: 1},
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := NewAccountsService(client).Delete(ctx, "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", 0)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "Delete error incorrect")
+	assert.Equal(t, int64(1), httpClient.requests.Load(), "requests incorrect")
+	assert.Equal(t, HedgingStats{}, client.HedgingStats(), "stats incorrect")
+}
+
+func TestHedger_learnedDelay(t *testing.T) {
+	h := newHedger(&HedgePolicy{Delay: time.Second, Percentile: 0.9})
+
+	delay, ok := h.delay()
+	assert.True(t, ok, "should hedge with the fallback delay")
+	assert.Equal(t, time.Second, delay, "fallback delay incorrect")
+
+	for i := 1; i <= 150; i++ {
+		h.observe(time.Duration(i)*time.Millisecond, false)
+	}
+	delay, ok = h.delay()
+
+	assert.True(t, ok, "should hedge with the learned delay")
+	assert.Equal(t, 141*time.Millisecond, delay, "learned delay incorrect")
+}
@@ -0,0 +1,28 @@
+package atomicfile
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.json")
+
+	assert.Nil(t, WriteFile(path, []byte(`{"a":1}`)), "Error should be nil")
+	assert.Nil(t, WriteFile(path, []byte(`{"a":2}`)), "Error should be nil")
+
+	data, err := os.ReadFile(path)
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, `{"a":2}`, string(data), "Content incorrect")
+	entries, _ := os.ReadDir(dir)
+	assert.Len(t, entries, 1, "Temporary files should be removed")
+}
+
+func TestWriteFile_missingDirectory(t *testing.T) {
+	err := WriteFile(filepath.Join(t.TempDir(), "missing", "state.json"), []byte("{}"))
+
+	assert.NotNil(t, err, "Error should not be nil")
+}
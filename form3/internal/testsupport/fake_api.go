@@ -0,0 +1,280 @@
+// Package testsupport holds the test doubles shared by the tests of the form3 packages
+package testsupport
+
+import (
+	"encoding/json"
+	"fmt"
+	"form3-interview-accountapi/form3"
+	"github.com/google/uuid"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// FakeAPIOptions turn off behaviours of the FakeAPI, to test clients of a non conforming API
+type FakeAPIOptions struct {
+	// IgnoreVersion accepts updates and deletes at any version
+	IgnoreVersion bool
+	// PlainErrors replaces the error messages by the HTTP status text
+	PlainErrors bool
+}
+
+// FakeAPI is an in-memory account API served over HTTP. It validates the ids, the country of created
+// accounts and the versions, pages the lists sorted by id, and records the requests changing accounts.
+type FakeAPI struct {
+	// URL is the base URL of the API, ending with /v1
+	URL string
+
+	opts     FakeAPIOptions
+	service  *form3.AccountsService
+	mu       sync.Mutex
+	accounts map[string]map[string]any
+	requests []string
+	creates  int
+	failures int
+}
+
+// NewFakeAPI starts a FakeAPI holding accounts, closed when the test ends
+func NewFakeAPI(t testing.TB, opts FakeAPIOptions, accounts ...form3.Account) *FakeAPI {
+	api := &FakeAPI{opts: opts, accounts: map[string]map[string]any{}}
+	for _, account := range accounts {
+		api.accounts[account.ID] = members(t, account)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(api.serve))
+	t.Cleanup(server.Close)
+	api.URL = server.URL + "/v1"
+
+	client, err := form3.NewRestClient(nil, form3.NewRestClientParams{BaseUrl: api.URL})
+	if err != nil {
+		t.Fatalf("Error creating RestClient: %v", err)
+	}
+	api.service = form3.NewAccountsService(client)
+
+	return api
+}
+
+// Service returns an AccountsService sending its requests to the API
+func (a *FakeAPI) Service() *form3.AccountsService {
+	return a.service
+}
+
+// Account returns the stored account by its id
+func (a *FakeAPI) Account(id string) (form3.Account, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	account := form3.Account{}
+	stored, ok := a.accounts[id]
+	if !ok {
+		return account, false
+	}
+	data, _ := json.Marshal(stored)
+	_ = json.Unmarshal(data, &account)
+
+	return account, true
+}
+
+// Len returns the number of accounts stored
+func (a *FakeAPI) Len() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return len(a.accounts)
+}
+
+// Requests returns the requests received other than GET, as the method followed by the account id
+func (a *FakeAPI) Requests() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return append([]string(nil), a.requests...)
+}
+
+// Creates returns the number of create requests received, failed or not
+func (a *FakeAPI) Creates() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.creates
+}
+
+// FailCreates makes the next count create requests fail with a 500 error
+func (a *FakeAPI) FailCreates(count int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.failures = count
+}
+
+func (a *FakeAPI) fail(w http.ResponseWriter, status int, message string) {
+	if a.opts.PlainErrors {
+		message = http.StatusText(status)
+	}
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error_message": message})
+}
+
+func (a *FakeAPI) serve(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	id := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/v1/organisation/accounts"), "/")
+	if r.Method != http.MethodGet {
+		a.requests = append(a.requests, strings.TrimSpace(r.Method+" "+id))
+	}
+	if _, err := uuid.Parse(id); id != "" && err != nil {
+		a.fail(w, http.StatusBadRequest, "id is not a valid uuid")
+		return
+	}
+	account, exists := a.accounts[id]
+
+	switch {
+	case r.Method == http.MethodPost:
+		a.create(w, r)
+	case r.Method == http.MethodGet && id == "":
+		a.list(w, r)
+	case !exists && r.Method == http.MethodDelete:
+		// the API answers the deletion of a missing account without a body
+		w.WriteHeader(http.StatusNotFound)
+	case !exists:
+		a.fail(w, http.StatusNotFound, "record "+id+" does not exist")
+	case r.Method == http.MethodGet:
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": account})
+	case !a.opts.IgnoreVersion && r.Method == http.MethodDelete && r.URL.Query().Get("version") != strconv.Itoa(version(account)):
+		a.fail(w, http.StatusConflict, "invalid version")
+	case r.Method == http.MethodDelete:
+		delete(a.accounts, id)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPatch:
+		a.update(w, r, account)
+	default:
+		a.fail(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (a *FakeAPI) create(w http.ResponseWriter, r *http.Request) {
+	a.creates++
+	if a.failures > 0 {
+		a.failures--
+		a.fail(w, http.StatusInternalServerError, "unavailable")
+		return
+	}
+
+	payload := struct {
+		Data map[string]any `json:"data"`
+	}{}
+	body, _ := io.ReadAll(r.Body)
+	if err := json.Unmarshal(body, &payload); err != nil || payload.Data == nil {
+		a.fail(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+	id, _ := payload.Data["id"].(string)
+	if _, err := uuid.Parse(id); err != nil {
+		a.fail(w, http.StatusBadRequest, "validation failure list:\nid in body must be of type uuid")
+		return
+	}
+	attributes, _ := payload.Data["attributes"].(map[string]any)
+	if country, _ := attributes["country"].(string); country == "" {
+		a.fail(w, http.StatusBadRequest, "validation failure list:\ncountry in body is required")
+		return
+	}
+	if _, ok := a.accounts[id]; ok {
+		a.fail(w, http.StatusConflict, "Account cannot be created as it violates a duplicate constraint")
+		return
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	payload.Data["version"] = 0
+	payload.Data["created_on"] = now
+	payload.Data["modified_on"] = now
+	a.accounts[id] = payload.Data
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(payload)
+}
+
+func (a *FakeAPI) list(w http.ResponseWriter, r *http.Request) {
+	ids := make([]string, 0, len(a.accounts))
+	for id := range a.accounts {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	size, _ := strconv.Atoi(r.URL.Query().Get("page[size]"))
+	if size <= 0 {
+		size = len(ids)
+	}
+	number, _ := strconv.Atoi(r.URL.Query().Get("page[number]"))
+	data := []any{}
+	for i := number * size; i < len(ids) && i < (number+1)*size; i++ {
+		data = append(data, a.accounts[ids[i]])
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{"data": data})
+}
+
+// update sets the attributes of the patch, removing the null ones
+func (a *FakeAPI) update(w http.ResponseWriter, r *http.Request, account map[string]any) {
+	payload := struct {
+		Data struct {
+			Version    int            `json:"version"`
+			Attributes map[string]any `json:"attributes"`
+		} `json:"data"`
+	}{}
+	body, _ := io.ReadAll(r.Body)
+	if err := json.Unmarshal(body, &payload); err != nil {
+		a.fail(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+	if !a.opts.IgnoreVersion && payload.Data.Version != version(account) {
+		a.fail(w, http.StatusConflict, "invalid version")
+		return
+	}
+
+	attributes, _ := account["attributes"].(map[string]any)
+	if attributes == nil {
+		attributes = map[string]any{}
+		account["attributes"] = attributes
+	}
+	for name, value := range payload.Data.Attributes {
+		if value == nil {
+			delete(attributes, name)
+			continue
+		}
+		attributes[name] = value
+	}
+	account["version"] = version(account) + 1
+	account["modified_on"] = time.Now().UTC().Format(time.RFC3339Nano)
+	_ = json.NewEncoder(w).Encode(map[string]any{"data": account})
+}
+
+// members returns the JSON members of the account as stored by the API
+func members(t testing.TB, account form3.Account) map[string]any {
+	data, err := json.Marshal(account)
+	if err != nil {
+		t.Fatalf("Error encoding account: %v", err)
+	}
+	decoded := map[string]any{}
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Error decoding account: %v", err)
+	}
+	decoded["version"] = account.Version
+
+	return decoded
+}
+
+func version(account map[string]any) int {
+	switch v := account["version"].(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	}
+
+	panic(fmt.Sprintf("unexpected version %v", account["version"]))
+}
@@ -0,0 +1,27 @@
+package testsupport
+
+import "fmt"
+
+// RecordingT records the failures and cleanups of a test instead of failing it, to test test helpers
+type RecordingT struct {
+	Errors   []string
+	cleanups []func()
+}
+
+func (t *RecordingT) Helper() {}
+
+func (t *RecordingT) Errorf(format string, args ...any) {
+	t.Errors = append(t.Errors, fmt.Sprintf(format, args...))
+}
+
+func (t *RecordingT) Cleanup(fn func()) {
+	t.cleanups = append(t.cleanups, fn)
+}
+
+// End runs the cleanups registered, in the order testing.T runs them
+func (t *RecordingT) End() {
+	for i := len(t.cleanups) - 1; i >= 0; i-- {
+		t.cleanups[i]()
+	}
+	t.cleanups = nil
+}
@@ -0,0 +1,62 @@
+package form3
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"strconv"
+)
+
+// FirstPage returns the page number of the first link
+func (l *Links) FirstPage() (int, bool) {
+	return linkPageNumber(l.First)
+}
+
+// PrevPage returns the page number of the prev link, false when there is no previous page
+func (l *Links) PrevPage() (int, bool) {
+	return linkPageNumber(l.Prev)
+}
+
+// NextPage returns the page number of the next link, false when there is no next page
+func (l *Links) NextPage() (int, bool) {
+	return linkPageNumber(l.Next)
+}
+
+// LastPage returns the page number of the last link
+func (l *Links) LastPage() (int, bool) {
+	return linkPageNumber(l.Last)
+}
+
+// linkPageNumber reads the page[number] query parameter of a link, first is assumed when it is omitted
+func linkPageNumber(link string) (int, bool) {
+	if link == "" {
+		return 0, false
+	}
+
+	linkURL, err := url.Parse(link)
+	if err != nil {
+		return 0, false
+	}
+
+	number := linkURL.Query().Get("page[number]")
+	if number == "" || number == "first" {
+		return 0, true
+	}
+
+	page, err := strconv.Atoi(number)
+	if err != nil {
+		return 0, false
+	}
+
+	return page, true
+}
+
+// UnmarshalJSON decodes the modelled metadata and keeps the rest in Extra
+func (m *Meta) UnmarshalJSON(data []byte) error {
+	type meta Meta
+	if err := json.Unmarshal(data, (*meta)(m)); err != nil {
+		return err
+	}
+
+	return collectUnknown(data, reflect.ValueOf(m))
+}
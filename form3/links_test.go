@@ -0,0 +1,75 @@
+package form3
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestRestClient_Do_linksAndMeta(t *testing.T) {
+	mockedHttpClient := mockedHttpClientHandler(func(req *http.Request) (*http.Response, error) {
+		body := `{"data":[{"id":"a1","type":"accounts","version":0}],` +
+			`"links":{"self":"/v1/organisation/accounts?page%5Bnumber%5D=1&page%5Bsize%5D=1",` +
+			`"first":"/v1/organisation/accounts?page%5Bnumber%5D=first&page%5Bsize%5D=1",` +
+			`"prev":"/v1/organisation/accounts?page%5Bnumber%5D=0&page%5Bsize%5D=1",` +
+			`"next":"/v1/organisation/accounts?page%5Bnumber%5D=2&page%5Bsize%5D=1",` +
+			`"last":"/v1/organisation/accounts?page%5Bnumber%5D=last&page%5Bsize%5D=1"},` +
+			`"meta":{"count":3,"total_pages":3,"server":"fake"}}`
+		return mockedResponse(http.StatusOK, body, nil), nil
+	})
+	client, _ := NewRestClient(mockedHttpClient, NewRestClientParams{BaseUrl: baseFakeUrl})
+	service := NewAccountsService(client)
+
+	_, resp, err := service.List(context.Background(), &ListOptions{PageNumber: 1, PageSize: 1})
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, "/v1/organisation/accounts?page%5Bnumber%5D=1&page%5Bsize%5D=1", resp.Links.Self, "Links.Self incorrect")
+	assert.Equal(t, 3, *resp.Meta.Count, "Meta.Count incorrect")
+	assert.Equal(t, 3, resp.Meta.TotalPages, "Meta.TotalPages incorrect")
+	assert.Equal(t, map[string]json.RawMessage{"server": json.RawMessage(`"fake"`)}, resp.Meta.Extra, "Meta.Extra incorrect")
+
+	page, ok := resp.Links.NextPage()
+	assert.True(t, ok, "Next page should exist")
+	assert.Equal(t, 2, page, "Next page incorrect")
+	page, ok = resp.Links.PrevPage()
+	assert.True(t, ok, "Prev page should exist")
+	assert.Equal(t, 0, page, "Prev page incorrect")
+	page, ok = resp.Links.FirstPage()
+	assert.True(t, ok, "First page should exist")
+	assert.Equal(t, 0, page, "First page incorrect")
+	_, ok = resp.Links.LastPage()
+	assert.False(t, ok, "Last page number is unknown")
+}
+
+func TestRestClient_Do_withoutLinks(t *testing.T) {
+	mockedHttpClient := mockedHttpClientHandler(func(req *http.Request) (*http.Response, error) {
+		return mockedResponse(http.StatusOK, `{"data":{"id":"a1"}}`, nil), nil
+	})
+	client, _ := NewRestClient(mockedHttpClient, NewRestClientParams{BaseUrl: baseFakeUrl})
+
+	_, resp, err := NewAccountsService(client).Get(context.Background(), "a1")
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Nil(t, resp.Links, "Links should be nil")
+	assert.Nil(t, resp.Meta, "Meta should be nil")
+}
+
+func TestLinks_NextPage_lastPage(t *testing.T) {
+	links := &Links{Self: "/v1/organisation/accounts?page%5Bnumber%5D=3"}
+
+	_, ok := links.NextPage()
+
+	assert.False(t, ok, "Next page should not exist")
+}
+
+func TestRestClient_LinkRequest(t *testing.T) {
+	c, _ := NewRestClient(nil, NewRestClientParams{BaseUrl: baseFakeUrl})
+
+	req, err := c.LinkRequest("/v1/organisation/accounts?page%5Bnumber%5D=2")
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, "GET", req.Method, "Request method expected error")
+	assert.Equal(t, "https://www.fake-api.com/v1/organisation/accounts?page%5Bnumber%5D=2", req.URL.String(), "Request URL expected error")
+}
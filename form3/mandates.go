@@ -0,0 +1,106 @@
+package form3
+
+import (
+	"context"
+	"fmt"
+)
+
+const mandatesBasePath = "transaction/mandates"
+
+// NewMandatesService returns a MandatesService instance.
+func NewMandatesService(client *RestClient) *MandatesService {
+	return &MandatesService{
+		service{
+			client,
+		},
+	}
+}
+
+// NewAccountMandate returns a Mandate linked to the given account, ready to be created.
+func NewAccountMandate(id string, account *Account, attributes *MandateAttributes) *Mandate {
+	return &Mandate{
+		ID:             id,
+		OrganisationID: account.OrganisationID,
+		Type:           MandateTypeMandates,
+		Attributes:     attributes,
+		Relationships: &MandateRelationships{
+			Account: &Relationship{
+				Data: []ResourceIdentifier{{ID: account.ID, Type: string(AcctTypeAccounts)}},
+			},
+		},
+	}
+}
+
+// Create creates a new mandate and returns it.
+func (s *MandatesService) Create(ctx context.Context, data *Mandate, opts ...CallOption) (*Mandate, *RestClientResponse, error) {
+	req, err := s.client.PostRequest(mandatesBasePath, data, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mandate := new(Mandate)
+	resp, err := s.client.Do(ctx, req.Request, mandate, opts...)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return mandate, resp, nil
+}
+
+// Get retrieves a mandate by its id
+func (s *MandatesService) Get(ctx context.Context, id string, opts ...CallOption) (*Mandate, *RestClientResponse, error) {
+	path := fmt.Sprintf("%s/%s", mandatesBasePath, id)
+
+	req, err := s.client.GetRequest(path, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mandate := new(Mandate)
+	resp, err := s.client.Do(ctx, req.Request, mandate, opts...)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return mandate, resp, nil
+}
+
+// List retrieves a page of mandates matching the paging and filter options
+func (s *MandatesService) List(ctx context.Context, listOpts *ListOptions, opts ...CallOption) ([]Mandate, *RestClientResponse, error) {
+	req, err := s.client.GetRequest(listPath(mandatesBasePath, listOpts), opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var mandates []Mandate
+	resp, err := s.client.Do(ctx, req.Request, &mandates, opts...)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return mandates, resp, nil
+}
+
+// Cancel cancels a mandate by its id and version, returning the updated mandate
+func (s *MandatesService) Cancel(ctx context.Context, id string, version int, opts ...CallOption) (*Mandate, *RestClientResponse, error) {
+	path := fmt.Sprintf("%s/%s", mandatesBasePath, id)
+	data := &MandatePatch{
+		ID:         id,
+		Type:       MandateTypeMandates,
+		Version:    version,
+		Attributes: &MandateAttributesPatch{Status: MandateStatusCancelled},
+	}
+
+	req, err := s.client.PatchRequest(path, data, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mandate := new(Mandate)
+	resp, err := s.client.Do(ctx, req.Request, mandate, opts...)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return mandate, resp, nil
+}
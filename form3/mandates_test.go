@@ -0,0 +1,122 @@
+package form3
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestNewAccountMandate(t *testing.T) {
+	account := &Account{ID: "a1b2c3", OrganisationID: "b", Type: AcctTypeAccounts}
+
+	mandate := NewAccountMandate("m1", account, &MandateAttributes{Scheme: MandateSchemeBacs})
+
+	assert.Equal(t, "m1", mandate.ID, "mandate.ID incorrect")
+	assert.Equal(t, "b", mandate.OrganisationID, "mandate.OrganisationID incorrect")
+	assert.Equal(t, MandateTypeMandates, mandate.Type, "mandate.Type incorrect")
+	assert.Equal(t, []ResourceIdentifier{{ID: "a1b2c3", Type: "accounts"}}, mandate.Relationships.Account.Data, "mandate account relationship incorrect")
+}
+
+func TestMandatesService_Create(t *testing.T) {
+	mockedHttpClient := mockedHttpClientHandler(func(req *http.Request) (*http.Response, error) {
+		testRequest(t, req, testRequestExpected{
+			method: "POST",
+			path:   "transaction/mandates",
+			body:   `{"data":{"id":"m1","organisation_id":"b","type":"mandates","attributes":{"scheme":"bacs","reference":"REF1"},"relationships":{"account":{"data":[{"id":"a1b2c3","type":"accounts"}]}},"version":0}}`,
+		})
+
+		body := `{"data":{"id":"m1","organisation_id":"b","type":"mandates","version":0,"attributes":{"scheme":"bacs","reference":"REF1","status":"pending"}}}`
+		return mockedResponse(http.StatusCreated, body, nil), nil
+	})
+	client, err := NewRestClient(mockedHttpClient, NewRestClientParams{BaseUrl: baseFakeUrl})
+	service := NewMandatesService(client)
+
+	account := &Account{ID: "a1b2c3", OrganisationID: "b", Type: AcctTypeAccounts}
+	mandate := NewAccountMandate("m1", account, &MandateAttributes{Scheme: MandateSchemeBacs, Reference: "REF1"})
+
+	newMandate, resp, err := service.Create(context.Background(), mandate)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.NotNil(t, resp, "Response should be not nil")
+	assert.Equal(t, http.StatusCreated, resp.StatusCode, "Response code incorrect")
+	assert.Equal(t, "m1", newMandate.ID, "newMandate.ID incorrect")
+	assert.Equal(t, MandateStatusPending, newMandate.Attributes.Status, "newMandate.Attributes.Status incorrect")
+}
+
+func TestMandatesService_Get(t *testing.T) {
+	mockedHttpClient := mockedHttpClientHandler(func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, "GET", req.Method)
+		assert.Equal(t, fmt.Sprintf("%s/%s", baseFakeUrl, "transaction/mandates/m1"), req.URL.String())
+
+		body := `{"data":{"id":"m1","organisation_id":"b","type":"mandates","version":2,"attributes":{"status":"active"}}}`
+		return mockedResponse(http.StatusOK, body, nil), nil
+	})
+	client, err := NewRestClient(mockedHttpClient, NewRestClientParams{BaseUrl: baseFakeUrl})
+	service := NewMandatesService(client)
+
+	mandate, resp, err := service.Get(context.Background(), "m1")
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "Response code incorrect")
+	assert.Equal(t, "m1", mandate.ID, "mandate.ID incorrect")
+	assert.Equal(t, 2, mandate.Version, "mandate.Version incorrect")
+	assert.Equal(t, MandateStatusActive, mandate.Attributes.Status, "mandate.Attributes.Status incorrect")
+}
+
+func TestMandatesService_Get_errorResponse(t *testing.T) {
+	mockedHttpClient := mockedHttpClientHandler(func(req *http.Request) (*http.Response, error) {
+		return mockedResponse(http.StatusNotFound, `{"error_message":"record m1 does not exist"}`, nil), nil
+	})
+	client, err := NewRestClient(mockedHttpClient, NewRestClientParams{BaseUrl: baseFakeUrl})
+	service := NewMandatesService(client)
+
+	mandate, resp, err := service.Get(context.Background(), "m1")
+
+	assert.Nil(t, mandate, "Mandate should be nil")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode, "Response code incorrect")
+	assert.Equal(t, "record m1 does not exist", err.Error(), "Error message expected")
+}
+
+func TestMandatesService_List(t *testing.T) {
+	mockedHttpClient := mockedHttpClientHandler(func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, "GET", req.Method)
+		assert.Equal(t, fmt.Sprintf("%s/%s", baseFakeUrl, "transaction/mandates?filter%5Bstatus%5D=active&page%5Bnumber%5D=1&page%5Bsize%5D=2"), req.URL.String())
+
+		body := `{"data":[{"id":"m1","type":"mandates","version":0},{"id":"m2","type":"mandates","version":1}]}`
+		return mockedResponse(http.StatusOK, body, nil), nil
+	})
+	client, err := NewRestClient(mockedHttpClient, NewRestClientParams{BaseUrl: baseFakeUrl})
+	service := NewMandatesService(client)
+
+	opts := &ListOptions{PageNumber: 1, PageSize: 2, Filter: map[string]string{"status": "active"}}
+	mandates, resp, err := service.List(context.Background(), opts)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "Response code incorrect")
+	assert.Len(t, mandates, 2, "Mandates length incorrect")
+	assert.Equal(t, "m2", mandates[1].ID, "mandates[1].ID incorrect")
+}
+
+func TestMandatesService_Cancel(t *testing.T) {
+	mockedHttpClient := mockedHttpClientHandler(func(req *http.Request) (*http.Response, error) {
+		testRequest(t, req, testRequestExpected{
+			method: "PATCH",
+			path:   "transaction/mandates/m1",
+			body:   `{"data":{"id":"m1","type":"mandates","version":2,"attributes":{"status":"cancelled"}}}`,
+		})
+
+		body := `{"data":{"id":"m1","type":"mandates","version":3,"attributes":{"status":"cancelled"}}}`
+		return mockedResponse(http.StatusOK, body, nil), nil
+	})
+	client, err := NewRestClient(mockedHttpClient, NewRestClientParams{BaseUrl: baseFakeUrl})
+	service := NewMandatesService(client)
+
+	mandate, resp, err := service.Cancel(context.Background(), "m1", 2)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "Response code incorrect")
+	assert.Equal(t, 3, mandate.Version, "mandate.Version incorrect")
+	assert.Equal(t, MandateStatusCancelled, mandate.Attributes.Status, "mandate.Attributes.Status incorrect")
+}
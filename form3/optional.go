@@ -0,0 +1,132 @@
+package form3
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// Optional holds a value that can be absent, explicitly null or set, so a false or empty
+// value can be sent on purpose. Absent Optional fields are left out by MarshalPatch.
+type Optional[T any] struct {
+	value   T
+	present bool
+	null    bool
+}
+
+// Some returns an Optional holding value
+func Some[T any](value T) Optional[T] {
+	return Optional[T]{value: value, present: true}
+}
+
+// Null returns an Optional that is sent as an explicit JSON null
+func Null[T any]() Optional[T] {
+	return Optional[T]{present: true, null: true}
+}
+
+// IsPresent reports whether the Optional is null or holds a value
+func (o Optional[T]) IsPresent() bool {
+	return o.present
+}
+
+// IsNull reports whether the Optional is an explicit null
+func (o Optional[T]) IsNull() bool {
+	return o.present && o.null
+}
+
+// Get returns the value and whether the Optional holds one
+func (o Optional[T]) Get() (T, bool) {
+	return o.value, o.present && !o.null
+}
+
+// MarshalJSON encodes the value, or null when it is null or absent
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if !o.present || o.null {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(o.value)
+}
+
+// UnmarshalJSON is only called for members present in the document, so the Optional becomes present
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	*o = Optional[T]{present: true}
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		o.null = true
+		return nil
+	}
+
+	return json.Unmarshal(data, &o.value)
+}
+
+func (o Optional[T]) isAbsent() bool {
+	return !o.present
+}
+
+type absenter interface {
+	isAbsent() bool
+}
+
+// MarshalPatch encodes the struct v as a JSON object like json.Marshal, leaving out the
+// Optional fields that are absent. Fields that are not Optional follow their json tags.
+func MarshalPatch(v any) ([]byte, error) {
+	value := reflect.Indirect(reflect.ValueOf(v))
+	if value.Kind() != reflect.Struct {
+		return nil, errors.New("patch must be a struct")
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+	valueType := value.Type()
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		name, omitEmpty, skip := jsonFieldName(field)
+		if skip {
+			continue
+		}
+
+		fieldValue := value.Field(i)
+		if optional, ok := fieldValue.Interface().(absenter); ok && optional.isAbsent() {
+			continue
+		}
+		if omitEmpty && fieldValue.IsZero() {
+			continue
+		}
+
+		data, err := json.Marshal(fieldValue.Interface())
+		if err != nil {
+			return nil, err
+		}
+		key, _ := json.Marshal(name)
+
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(data)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+func jsonFieldName(field reflect.StructField) (name string, omitEmpty bool, skip bool) {
+	if !field.IsExported() {
+		return "", false, true
+	}
+
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+
+	name, options, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+
+	return name, strings.Contains(","+options+",", ",omitempty,"), false
+}
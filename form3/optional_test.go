@@ -0,0 +1,58 @@
+package form3
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestOptional_states(t *testing.T) {
+	var absent Optional[string]
+	assert.False(t, absent.IsPresent(), "Zero Optional should be absent")
+
+	null := Null[string]()
+	assert.True(t, null.IsPresent(), "Null Optional should be present")
+	assert.True(t, null.IsNull(), "Null Optional should be null")
+
+	value, ok := Some("").Get()
+	assert.True(t, ok, "Some Optional should hold a value")
+	assert.Equal(t, "", value, "Some Optional value incorrect")
+}
+
+func TestAccountAttributesPatch_MarshalJSON(t *testing.T) {
+	patch := AccountAttributesPatch{
+		AccountMatchingOptOut:   Some(false),
+		SecondaryIdentification: Null[string](),
+		Name:                    Some([]string{"cristian"}),
+		AlternativeNames:        Some([]string{}),
+	}
+
+	data, err := json.Marshal(patch)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, `{"account_matching_opt_out":false,"alternative_names":[],"name":["cristian"],"secondary_identification":null}`, string(data), "Patch JSON incorrect")
+}
+
+func TestAccountAttributesPatch_UnmarshalJSON(t *testing.T) {
+	patch := AccountAttributesPatch{}
+
+	err := json.Unmarshal([]byte(`{"switched":false,"iban":null,"bic":"NWBKGB22"}`), &patch)
+
+	assert.Nil(t, err, "Error should be nil")
+	switched, ok := patch.Switched.Get()
+	assert.True(t, ok, "Switched should hold a value")
+	assert.False(t, switched, "Switched value incorrect")
+	assert.True(t, patch.Iban.IsNull(), "Iban should be null")
+	bic, _ := patch.Bic.Get()
+	assert.Equal(t, "NWBKGB22", bic, "Bic value incorrect")
+	assert.False(t, patch.JointAccount.IsPresent(), "JointAccount should be absent")
+
+	data, _ := json.Marshal(patch)
+	assert.Equal(t, `{"bic":"NWBKGB22","iban":null,"switched":false}`, string(data), "Patch should round trip")
+}
+
+func TestMarshalPatch_notStruct(t *testing.T) {
+	_, err := MarshalPatch("foo")
+
+	assert.NotNil(t, err, "Error should be not nil")
+}
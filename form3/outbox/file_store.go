@@ -0,0 +1,192 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"form3-interview-accountapi/form3/internal/atomicfile"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// FileStore keeps the entries in a file of JSON lines, each Put appending the entry to it. The file is read
+// once and the entries kept in memory, so a FileStore must be the only one writing its file. The file is
+// compacted to one line per entry when the lines outnumber the entries twice, and by Prune.
+type FileStore struct {
+	Path string
+
+	mu      sync.Mutex
+	entries map[string]Entry
+	lines   int
+}
+
+func (s *FileStore) Put(ctx context.Context, entry *Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return err
+	}
+	if err := s.append(entry); err != nil {
+		return err
+	}
+	s.entries[entry.Account.ID] = *entry
+	s.lines++
+
+	if s.lines > 2*len(s.entries) {
+		return s.compact()
+	}
+
+	return nil
+}
+
+func (s *FileStore) Get(ctx context.Context, accountID string) (*Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	entry, ok := s.entries[accountID]
+	if !ok {
+		return nil, nil
+	}
+
+	return &entry, nil
+}
+
+func (s *FileStore) Pending(ctx context.Context) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	var pending []Entry
+	for _, entry := range s.entries {
+		if entry.Status == StatusPending {
+			pending = append(pending, entry)
+		}
+	}
+	sort.SliceStable(pending, func(i, j int) bool {
+		return pending[i].EnqueuedAt.Before(pending[j].EnqueuedAt)
+	})
+
+	return pending, nil
+}
+
+// Prune removes the delivered entries updated before the given time and compacts the file
+func (s *FileStore) Prune(ctx context.Context, before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return 0, err
+	}
+
+	pruned := 0
+	for id, entry := range s.entries {
+		if entry.Status == StatusDelivered && entry.UpdatedAt.Before(before) {
+			delete(s.entries, id)
+			pruned++
+		}
+	}
+	if pruned == 0 {
+		return 0, nil
+	}
+
+	return pruned, s.compact()
+}
+
+// load reads the file the first time the store is used, the last version of each entry is kept.
+// A last line without line break was being appended when the process stopped, it is dropped.
+func (s *FileStore) load() error {
+	if s.entries != nil {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.Path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	entries := map[string]Entry{}
+	count := 0
+	torn := false
+	lines := bytes.Split(data, []byte("\n"))
+	for i, line := range lines {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		entry := Entry{}
+		if err = json.Unmarshal(line, &entry); err != nil {
+			if i == len(lines)-1 {
+				torn = true
+				break
+			}
+			return fmt.Errorf("reading line %d of %s: %w", i+1, s.Path, err)
+		}
+		entries[entry.Account.ID] = entry
+		count++
+	}
+	s.entries = entries
+	s.lines = count
+
+	// the next entries would be appended to the torn line
+	if torn {
+		return s.compact()
+	}
+
+	return nil
+}
+
+// append writes the entry at the end of the file and flushes it to disk
+func (s *FileStore) append(entry *Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(s.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err = file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	if err = file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// compact replaces the file by one line per entry
+func (s *FileStore) compact() error {
+	ids := make([]string, 0, len(s.entries))
+	for id := range s.entries {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var data []byte
+	for _, id := range ids {
+		line, err := json.Marshal(s.entries[id])
+		if err != nil {
+			return err
+		}
+		data = append(append(data, line...), '\n')
+	}
+	if err := atomicfile.WriteFile(s.Path, data); err != nil {
+		return err
+	}
+	s.lines = len(s.entries)
+
+	return nil
+}
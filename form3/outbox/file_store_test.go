@@ -0,0 +1,115 @@
+package outbox
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testStore checks the behaviour shared by the Store implementations
+func testStore(t *testing.T, store Store) {
+	t.Helper()
+	ctx := context.Background()
+	enqueuedAt := time.Date(2023, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	missing, err := store.Get(ctx, "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+	assert.Nil(t, err, "Get error should be nil")
+	assert.Nil(t, missing, "missing entry should be nil")
+
+	second := &Entry{Account: testAccount("b7a1c6f2-1e2c-4bde-8d1b-3c3f1f0e9a11"), Status: StatusPending, EnqueuedAt: enqueuedAt.Add(time.Minute), UpdatedAt: enqueuedAt}
+	first := &Entry{Account: testAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc"), Status: StatusPending, EnqueuedAt: enqueuedAt, UpdatedAt: enqueuedAt}
+	delivered := &Entry{Account: testAccount("c0f5b2d4-6a9e-4f4b-9b8e-6e7a2c1d3f22"), Status: StatusDelivered, Attempts: 1, EnqueuedAt: enqueuedAt, UpdatedAt: enqueuedAt}
+	for _, entry := range []*Entry{second, first, delivered} {
+		assert.Nil(t, store.Put(ctx, entry), "Put error should be nil")
+	}
+
+	pending, err := store.Pending(ctx)
+	assert.Nil(t, err, "Pending error should be nil")
+	assert.Equal(t, []Entry{*first, *second}, pending, "pending entries incorrect")
+
+	first.Status = StatusFailed
+	first.Attempts = 3
+	first.LastError = "boom"
+	assert.Nil(t, store.Put(ctx, first), "Put error should be nil")
+
+	entry, err := store.Get(ctx, first.Account.ID)
+	assert.Nil(t, err, "Get error should be nil")
+	assert.Equal(t, first, entry, "entry incorrect")
+
+	pending, err = store.Pending(ctx)
+	assert.Nil(t, err, "Pending error should be nil")
+	assert.Equal(t, []Entry{*second}, pending, "pending entries after update incorrect")
+
+	recent := &Entry{Account: testAccount("d9e8f7a6-5b4c-4d3e-8f2a-1b0c9d8e7f33"), Status: StatusDelivered, Attempts: 1, EnqueuedAt: enqueuedAt, UpdatedAt: enqueuedAt.Add(time.Hour)}
+	assert.Nil(t, store.Put(ctx, recent), "Put error should be nil")
+
+	pruned, err := store.Prune(ctx, enqueuedAt.Add(time.Minute))
+	assert.Nil(t, err, "Prune error should be nil")
+	assert.Equal(t, 1, pruned, "only the old delivered entry should be pruned")
+	entry, _ = store.Get(ctx, delivered.Account.ID)
+	assert.Nil(t, entry, "pruned entry should be removed")
+	entry, _ = store.Get(ctx, recent.Account.ID)
+	assert.NotNil(t, entry, "recent delivered entry should be kept")
+	entry, _ = store.Get(ctx, first.Account.ID)
+	assert.NotNil(t, entry, "failed entry should be kept")
+}
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.json")
+
+	testStore(t, &FileStore{Path: path})
+
+	reopened, err := (&FileStore{Path: path}).Pending(context.Background())
+	assert.Nil(t, err, "Pending error should be nil")
+	assert.Len(t, reopened, 1, "entries should persist in the file")
+}
+
+func TestFileStore_corrupted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.json")
+	_ = os.WriteFile(path, []byte("{\n{}\n"), 0o600)
+
+	_, err := (&FileStore{Path: path}).Pending(context.Background())
+
+	assert.NotNil(t, err, "Pending error should not be nil")
+}
+
+func TestFileStore_tornLastLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.json")
+	store := &FileStore{Path: path}
+	entry := &Entry{Account: testAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc"), Status: StatusPending}
+	_ = store.Put(context.Background(), entry)
+	file, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	_, _ = file.WriteString(`{"account":{"id":"b7a1`)
+	_ = file.Close()
+
+	reopened := &FileStore{Path: path}
+	pending, err := reopened.Pending(context.Background())
+	assert.Nil(t, err, "Pending error should be nil")
+	assert.Len(t, pending, 1, "the torn entry should be dropped")
+
+	second := &Entry{Account: testAccount("b7a1c6f2-1e2c-4bde-8d1b-3c3f1f0e9a11"), Status: StatusPending}
+	assert.Nil(t, reopened.Put(context.Background(), second), "Put error should be nil")
+	pending, err = (&FileStore{Path: path}).Pending(context.Background())
+	assert.Nil(t, err, "Pending error should be nil")
+	assert.Len(t, pending, 2, "entries after the torn line should be readable")
+}
+
+func TestFileStore_compacts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.json")
+	store := &FileStore{Path: path}
+	entry := &Entry{Account: testAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc"), Status: StatusPending}
+
+	for attempts := 1; attempts <= 5; attempts++ {
+		entry.Attempts = attempts
+		assert.Nil(t, store.Put(context.Background(), entry), "Put error should be nil")
+	}
+
+	data, _ := os.ReadFile(path)
+	assert.LessOrEqual(t, strings.Count(string(data), "\n"), 2, "the file should be compacted")
+	saved, _ := (&FileStore{Path: path}).Get(context.Background(), entry.Account.ID)
+	assert.Equal(t, 5, saved.Attempts, "the last version should be kept")
+}
@@ -0,0 +1,240 @@
+// Package outbox persists account creations before sending them, so they are delivered
+// even if the process stops before the API confirms them.
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"form3-interview-accountapi/form3"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusDelivered Status = "delivered"
+	// StatusFailed is set when the API rejects the account or the attempts are exhausted, it is not replayed
+	StatusFailed Status = "failed"
+)
+
+// ErrAlreadyEnqueued is returned by Create for an account the outbox already has an entry for
+var ErrAlreadyEnqueued = errors.New("outbox: account already enqueued")
+
+// Entry is an account creation kept in the outbox
+type Entry struct {
+	Account    form3.Account `json:"account"`
+	Status     Status        `json:"status"`
+	Attempts   int           `json:"attempts"`
+	LastError  string        `json:"last_error,omitempty"`
+	EnqueuedAt time.Time     `json:"enqueued_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+}
+
+// Store persists the entries of the outbox, keyed by account ID
+type Store interface {
+	// Put creates or replaces the entry of its account
+	Put(ctx context.Context, entry *Entry) error
+	// Get returns the entry of an account, nil if there is none
+	Get(ctx context.Context, accountID string) (*Entry, error)
+	// Pending returns the pending entries in the order they were enqueued
+	Pending(ctx context.Context) ([]Entry, error)
+	// Prune removes the delivered entries last updated before the given time and returns how many
+	Prune(ctx context.Context, before time.Time) (int, error)
+}
+
+type Options struct {
+	// MaxAttempts marks an entry failed after that many failed deliveries, unlimited by default
+	MaxAttempts int
+	// Retention is how long delivered entries are kept for Status, forever when 0. See Outbox.Prune
+	Retention   time.Duration
+	CallOptions []form3.CallOption
+}
+
+type Outbox struct {
+	service form3.AccountsAPI
+	store   Store
+	opts    Options
+	now     func() time.Time
+	// mu serialises the enqueuing of the accounts, for the check of their entry
+	mu sync.Mutex
+}
+
+// New returns an Outbox instance.
+func New(service form3.AccountsAPI, store Store, opts Options) *Outbox {
+	return &Outbox{service: service, store: store, opts: opts, now: time.Now}
+}
+
+// Create stores the account in the outbox and then delivers it.
+// When the delivery fails the entry stays pending, to be sent again by Replay, unless it failed for good.
+// ErrAlreadyEnqueued is returned when the outbox has an entry for the account, whatever its status.
+// The check is not shared with other processes using the same store.
+func (o *Outbox) Create(ctx context.Context, account *form3.Account) (*form3.Account, error) {
+	entry, err := o.enqueue(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+
+	return o.deliver(ctx, entry)
+}
+
+func (o *Outbox) enqueue(ctx context.Context, account *form3.Account) (*Entry, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	existing, err := o.store.Get(ctx, account.ID)
+	if err != nil {
+		return nil, fmt.Errorf("reading account %s: %w", account.ID, err)
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("%w: %s", ErrAlreadyEnqueued, account.ID)
+	}
+
+	now := o.now()
+	entry := &Entry{Account: *account, Status: StatusPending, EnqueuedAt: now, UpdatedAt: now}
+	if err = o.store.Put(ctx, entry); err != nil {
+		return nil, fmt.Errorf("storing account %s: %w", account.ID, err)
+	}
+
+	return entry, nil
+}
+
+// Replay delivers the pending entries, it is meant to be called on startup. It prunes the store first.
+// It returns the number of entries delivered and stops at the first error of the store.
+func (o *Outbox) Replay(ctx context.Context) (int, error) {
+	if _, err := o.Prune(ctx); err != nil {
+		return 0, err
+	}
+
+	entries, err := o.store.Pending(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for i := range entries {
+		if ctx.Err() != nil {
+			return delivered, ctx.Err()
+		}
+		_, err = o.deliver(ctx, &entries[i])
+		if entries[i].Status == StatusDelivered {
+			delivered++
+		}
+		var storeErr *storeError
+		if errors.As(err, &storeErr) {
+			return delivered, err
+		}
+	}
+
+	return delivered, nil
+}
+
+// Prune removes the delivered entries older than the retention from the store, and returns how many.
+// Call it periodically in long running processes, nothing is removed when the retention is 0.
+func (o *Outbox) Prune(ctx context.Context) (int, error) {
+	if o.opts.Retention <= 0 {
+		return 0, nil
+	}
+
+	return o.store.Prune(ctx, o.now().Add(-o.opts.Retention))
+}
+
+// Status returns the entry of an account with its delivery status, nil if it was never enqueued or was pruned
+func (o *Outbox) Status(ctx context.Context, accountID string) (*Entry, error) {
+	return o.store.Get(ctx, accountID)
+}
+
+// storeError is an error of the store while recording a delivery
+type storeError struct {
+	accountID string
+	err       error
+}
+
+func (e *storeError) Error() string {
+	return fmt.Sprintf("storing delivery of account %s: %s", e.accountID, e.err)
+}
+
+func (e *storeError) Unwrap() error {
+	return e.err
+}
+
+// deliver creates the account of the entry, or gets it if it was already created, and records the outcome
+func (o *Outbox) deliver(ctx context.Context, entry *Entry) (*form3.Account, error) {
+	account, deliveryErr := o.createOrGet(ctx, &entry.Account)
+
+	entry.Attempts++
+	entry.UpdatedAt = o.now()
+	switch {
+	case deliveryErr == nil:
+		entry.Status = StatusDelivered
+		entry.LastError = ""
+	case ctx.Err() != nil:
+		// the attempt was abandoned, not refused
+		entry.Attempts--
+		return nil, deliveryErr
+	default:
+		entry.LastError = deliveryErr.Error()
+		if isPermanent(deliveryErr) || (o.opts.MaxAttempts > 0 && entry.Attempts >= o.opts.MaxAttempts) {
+			entry.Status = StatusFailed
+		}
+	}
+
+	if err := o.store.Put(ctx, entry); err != nil {
+		return account, &storeError{accountID: entry.Account.ID, err: err}
+	}
+
+	return account, unwrapDelivery(deliveryErr)
+}
+
+// deliveryError keeps the status code of a failed creation
+type deliveryError struct {
+	statusCode int
+	err        error
+}
+
+func (e *deliveryError) Error() string {
+	return e.err.Error()
+}
+
+func (o *Outbox) createOrGet(ctx context.Context, account *form3.Account) (*form3.Account, error) {
+	created, resp, err := o.service.Create(ctx, account, o.opts.CallOptions...)
+	if err == nil {
+		return created, nil
+	}
+	if resp == nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusConflict {
+		return nil, &deliveryError{statusCode: resp.StatusCode, err: err}
+	}
+
+	// a previous attempt may have created it before the process stopped
+	existing, _, getErr := o.service.Get(ctx, account.ID, o.opts.CallOptions...)
+	if getErr != nil {
+		return nil, err
+	}
+
+	return existing, nil
+}
+
+// isPermanent reports whether the API refused the account, so sending it again would fail the same way
+func isPermanent(err error) bool {
+	deliveryErr, ok := err.(*deliveryError)
+	if !ok {
+		return false
+	}
+
+	return deliveryErr.statusCode >= 400 && deliveryErr.statusCode < 500 &&
+		deliveryErr.statusCode != http.StatusRequestTimeout && deliveryErr.statusCode != http.StatusTooManyRequests
+}
+
+func unwrapDelivery(err error) error {
+	if deliveryErr, ok := err.(*deliveryError); ok {
+		return deliveryErr.err
+	}
+
+	return err
+}
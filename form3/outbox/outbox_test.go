@@ -0,0 +1,155 @@
+package outbox
+
+import (
+	"context"
+	"form3-interview-accountapi/form3"
+	"form3-interview-accountapi/form3/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testAccount(id string) form3.Account {
+	return form3.Account{
+		ID:             id,
+		OrganisationID: "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c",
+		Type:           form3.AcctTypeAccounts,
+		Attributes: &form3.AccountAttributes{
+			Country: form3.CountryCodeBelgium,
+			Name:    []string{"Samantha Holder"},
+		},
+	}
+}
+
+func newTestOutbox(t *testing.T, opts Options, accounts ...form3.Account) (*testsupport.FakeAPI, *Outbox, Store) {
+	api := testsupport.NewFakeAPI(t, testsupport.FakeAPIOptions{}, accounts...)
+	store := &FileStore{Path: filepath.Join(t.TempDir(), "outbox.json")}
+
+	return api, New(api.Service(), store, opts), store
+}
+
+func TestOutbox_Create(t *testing.T) {
+	_, outbox, _ := newTestOutbox(t, Options{})
+	account := testAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+
+	created, err := outbox.Create(context.Background(), &account)
+
+	assert.Nil(t, err, "Create error should be nil")
+	assert.Equal(t, account.ID, created.ID, "created.ID incorrect")
+	entry, err := outbox.Status(context.Background(), account.ID)
+	assert.Nil(t, err, "Status error should be nil")
+	assert.Equal(t, StatusDelivered, entry.Status, "Status incorrect")
+	assert.Equal(t, 1, entry.Attempts, "Attempts incorrect")
+}
+
+func TestOutbox_Create_alreadyEnqueued(t *testing.T) {
+	api, outbox, _ := newTestOutbox(t, Options{})
+	api.FailCreates(1)
+	account := testAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+	_, _ = outbox.Create(context.Background(), &account)
+
+	_, err := outbox.Create(context.Background(), &account)
+
+	assert.ErrorIs(t, err, ErrAlreadyEnqueued, "Create error incorrect")
+	entry, _ := outbox.Status(context.Background(), account.ID)
+	assert.Equal(t, 1, entry.Attempts, "entry should be kept")
+	assert.Equal(t, 1, api.Creates(), "creates incorrect")
+}
+
+func TestOutbox_Create_failureStaysPending(t *testing.T) {
+	api, outbox, _ := newTestOutbox(t, Options{})
+	api.FailCreates(1)
+	account := testAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+
+	_, err := outbox.Create(context.Background(), &account)
+
+	assert.EqualError(t, err, "unavailable", "Create error incorrect")
+	entry, _ := outbox.Status(context.Background(), account.ID)
+	assert.Equal(t, StatusPending, entry.Status, "Status incorrect")
+	assert.Equal(t, "unavailable", entry.LastError, "LastError incorrect")
+
+	delivered, err := outbox.Replay(context.Background())
+
+	assert.Nil(t, err, "Replay error should be nil")
+	assert.Equal(t, 1, delivered, "delivered incorrect")
+	entry, _ = outbox.Status(context.Background(), account.ID)
+	assert.Equal(t, StatusDelivered, entry.Status, "Status after replay incorrect")
+	assert.Equal(t, 2, entry.Attempts, "Attempts incorrect")
+}
+
+func TestOutbox_Replay_alreadyCreated(t *testing.T) {
+	account := testAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+	// the process stopped after the API created the account, before the delivery was stored
+	_, outbox, store := newTestOutbox(t, Options{}, account)
+	_ = store.Put(context.Background(), &Entry{Account: account, Status: StatusPending})
+
+	delivered, err := outbox.Replay(context.Background())
+
+	assert.Nil(t, err, "Replay error should be nil")
+	assert.Equal(t, 1, delivered, "delivered incorrect")
+	entry, _ := outbox.Status(context.Background(), account.ID)
+	assert.Equal(t, StatusDelivered, entry.Status, "Status incorrect")
+}
+
+func TestOutbox_Create_rejected(t *testing.T) {
+	api, outbox, _ := newTestOutbox(t, Options{})
+	account := testAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+	account.Attributes.Country = ""
+
+	_, err := outbox.Create(context.Background(), &account)
+
+	assert.EqualError(t, err, "validation failure list:\ncountry in body is required", "Create error incorrect")
+	entry, _ := outbox.Status(context.Background(), account.ID)
+	assert.Equal(t, StatusFailed, entry.Status, "Status incorrect")
+
+	delivered, err := outbox.Replay(context.Background())
+
+	assert.Nil(t, err, "Replay error should be nil")
+	assert.Equal(t, 0, delivered, "delivered incorrect")
+	assert.Equal(t, 1, api.Creates(), "failed entries should not be replayed")
+}
+
+func TestOutbox_MaxAttempts(t *testing.T) {
+	api, outbox, _ := newTestOutbox(t, Options{MaxAttempts: 2})
+	api.FailCreates(5)
+	account := testAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+
+	_, _ = outbox.Create(context.Background(), &account)
+	_, _ = outbox.Replay(context.Background())
+	_, _ = outbox.Replay(context.Background())
+
+	entry, _ := outbox.Status(context.Background(), account.ID)
+	assert.Equal(t, StatusFailed, entry.Status, "Status incorrect")
+	assert.Equal(t, 2, entry.Attempts, "Attempts incorrect")
+	assert.Equal(t, 2, api.Creates(), "creates incorrect")
+}
+
+func TestOutbox_Replay_prunesDelivered(t *testing.T) {
+	_, outbox, _ := newTestOutbox(t, Options{Retention: time.Hour})
+	now := time.Date(2023, 3, 1, 10, 0, 0, 0, time.UTC)
+	outbox.now = func() time.Time { return now }
+	account := testAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+	_, _ = outbox.Create(context.Background(), &account)
+
+	now = now.Add(30 * time.Minute)
+	_, err := outbox.Replay(context.Background())
+	assert.Nil(t, err, "Replay error should be nil")
+	entry, _ := outbox.Status(context.Background(), account.ID)
+	assert.NotNil(t, entry, "entry within the retention should be kept")
+
+	now = now.Add(time.Hour)
+	_, err = outbox.Replay(context.Background())
+	assert.Nil(t, err, "Replay error should be nil")
+	entry, _ = outbox.Status(context.Background(), account.ID)
+	assert.Nil(t, entry, "entry past the retention should be pruned")
+}
+
+func TestOutbox_Status_unknown(t *testing.T) {
+	_, outbox, _ := newTestOutbox(t, Options{})
+
+	entry, err := outbox.Status(context.Background(), "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+
+	assert.Nil(t, err, "Status error should be nil")
+	assert.Nil(t, entry, "entry should be nil")
+}
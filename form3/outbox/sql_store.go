@@ -0,0 +1,165 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SQLStore keeps the entries in a table of a database/sql database, one row per account. The times are
+// written in UTC:
+//
+//	CREATE TABLE outbox (
+//		account_id  VARCHAR(36) PRIMARY KEY,
+//		account     TEXT NOT NULL,
+//		status      VARCHAR(16) NOT NULL,
+//		attempts    INTEGER NOT NULL,
+//		last_error  TEXT NOT NULL,
+//		enqueued_at TIMESTAMP NOT NULL,
+//		updated_at  TIMESTAMP NOT NULL
+//	)
+//
+// CreateTable creates it when it does not exist.
+type SQLStore struct {
+	DB *sql.DB
+	// Table is the name of the table, outbox by default
+	Table string
+	// Placeholder returns the parameter placeholder at a position starting at 1, ? by default.
+	// Use DollarPlaceholder for PostgreSQL
+	Placeholder func(n int) string
+}
+
+// DollarPlaceholder returns the $n placeholders of PostgreSQL
+func DollarPlaceholder(n int) string {
+	return fmt.Sprintf("$%d", n)
+}
+
+const sqlColumns = "account_id, account, status, attempts, last_error, enqueued_at, updated_at"
+
+// CreateTable creates the table of the store if it does not exist
+func (s *SQLStore) CreateTable(ctx context.Context) error {
+	_, err := s.DB.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	account_id VARCHAR(36) PRIMARY KEY,
+	account TEXT NOT NULL,
+	status VARCHAR(16) NOT NULL,
+	attempts INTEGER NOT NULL,
+	last_error TEXT NOT NULL,
+	enqueued_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL
+)`, s.table()))
+
+	return err
+}
+
+// Put inserts the row of the account, or updates it when there is one, in a single upsert statement.
+// INSERT ... ON CONFLICT is supported by PostgreSQL and SQLite among others.
+func (s *SQLStore) Put(ctx context.Context, entry *Entry) error {
+	account, err := json.Marshal(entry.Account)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.DB.ExecContext(ctx,
+		fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) ON CONFLICT (account_id) DO UPDATE SET "+
+			"account = excluded.account, status = excluded.status, attempts = excluded.attempts, "+
+			"last_error = excluded.last_error, enqueued_at = excluded.enqueued_at, updated_at = excluded.updated_at",
+			s.table(), sqlColumns, s.placeholders(7)),
+		entry.Account.ID, string(account), string(entry.Status), entry.Attempts, entry.LastError, entry.EnqueuedAt.UTC(), entry.UpdatedAt.UTC(),
+	)
+
+	return err
+}
+
+func (s *SQLStore) Get(ctx context.Context, accountID string) (*Entry, error) {
+	rows, err := s.DB.QueryContext(ctx,
+		fmt.Sprintf("SELECT %s FROM %s WHERE account_id = %s", sqlColumns, s.table(), s.placeholder(1)),
+		accountID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := scanEntries(rows)
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+
+	return &entries[0], nil
+}
+
+func (s *SQLStore) Pending(ctx context.Context) ([]Entry, error) {
+	rows, err := s.DB.QueryContext(ctx,
+		fmt.Sprintf("SELECT %s FROM %s WHERE status = %s ORDER BY enqueued_at", sqlColumns, s.table(), s.placeholder(1)),
+		string(StatusPending),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanEntries(rows)
+}
+
+// Prune deletes the rows of the delivered entries updated before the given time
+func (s *SQLStore) Prune(ctx context.Context, before time.Time) (int, error) {
+	result, err := s.DB.ExecContext(ctx,
+		fmt.Sprintf("DELETE FROM %s WHERE status = %s AND updated_at < %s", s.table(), s.placeholder(1), s.placeholder(2)),
+		string(StatusDelivered), before.UTC(),
+	)
+	if err != nil {
+		return 0, err
+	}
+	pruned, err := result.RowsAffected()
+
+	return int(pruned), err
+}
+
+func scanEntries(rows *sql.Rows) ([]Entry, error) {
+	defer rows.Close()
+
+	var entries []Entry
+	for rows.Next() {
+		var (
+			accountID string
+			account   string
+			entry     Entry
+		)
+		err := rows.Scan(&accountID, &account, &entry.Status, &entry.Attempts, &entry.LastError, &entry.EnqueuedAt, &entry.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal([]byte(account), &entry.Account); err != nil {
+			return nil, fmt.Errorf("decoding account %s: %w", accountID, err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+func (s *SQLStore) table() string {
+	if s.Table == "" {
+		return "outbox"
+	}
+
+	return s.Table
+}
+
+func (s *SQLStore) placeholder(n int) string {
+	if s.Placeholder == nil {
+		return "?"
+	}
+
+	return s.Placeholder(n)
+}
+
+func (s *SQLStore) placeholders(count int) string {
+	placeholders := make([]string, count)
+	for i := range placeholders {
+		placeholders[i] = s.placeholder(i + 1)
+	}
+
+	return strings.Join(placeholders, ", ")
+}
@@ -0,0 +1,148 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeDatabase is a database/sql driver understanding the statements of SQLStore, rows are kept by account_id
+type fakeDatabase struct {
+	mu      sync.Mutex
+	rows    map[string][]driver.Value
+	queries []string
+}
+
+func newFakeDB(t *testing.T) (*fakeDatabase, *sql.DB) {
+	database := &fakeDatabase{rows: map[string][]driver.Value{}}
+	db := sql.OpenDB(database)
+	t.Cleanup(func() { _ = db.Close() })
+
+	return database, db
+}
+
+func (d *fakeDatabase) Connect(ctx context.Context) (driver.Conn, error) { return fakeConn{d}, nil }
+func (d *fakeDatabase) Driver() driver.Driver                            { return nil }
+
+type fakeConn struct{ database *fakeDatabase }
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return fakeStmt{database: c.database, query: query}, nil
+}
+func (c fakeConn) Close() error              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error) { return fakeTx{}, nil }
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeStmt struct {
+	database *fakeDatabase
+	query    string
+}
+
+func (s fakeStmt) Close() error  { return nil }
+func (s fakeStmt) NumInput() int { return -1 }
+
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	d := s.database
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.queries = append(d.queries, s.query)
+
+	switch {
+	case strings.HasPrefix(s.query, "CREATE"):
+		return driver.RowsAffected(0), nil
+	case strings.HasPrefix(s.query, "INSERT") && strings.Contains(s.query, "ON CONFLICT (account_id) DO UPDATE"):
+		d.rows[args[0].(string)] = args
+		return driver.RowsAffected(1), nil
+	case strings.HasPrefix(s.query, "DELETE"):
+		deleted := 0
+		for id, row := range d.rows {
+			if row[2] == args[0] && row[6].(time.Time).Before(args[1].(time.Time)) {
+				delete(d.rows, id)
+				deleted++
+			}
+		}
+		return driver.RowsAffected(deleted), nil
+	}
+
+	return nil, errors.New("unexpected statement " + s.query)
+}
+
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	d := s.database
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.queries = append(d.queries, s.query)
+
+	var rows [][]driver.Value
+	switch {
+	case strings.Contains(s.query, "WHERE account_id"):
+		if row, ok := d.rows[args[0].(string)]; ok {
+			rows = append(rows, row)
+		}
+	case strings.Contains(s.query, "WHERE status"):
+		for _, row := range d.rows {
+			if row[2] == args[0] {
+				rows = append(rows, row)
+			}
+		}
+		sort.Slice(rows, func(i, j int) bool { return rows[i][5].(time.Time).Before(rows[j][5].(time.Time)) })
+	default:
+		return nil, errors.New("unexpected query " + s.query)
+	}
+
+	return &fakeRows{rows: rows}, nil
+}
+
+type fakeRows struct{ rows [][]driver.Value }
+
+func (r *fakeRows) Columns() []string {
+	return strings.Split(sqlColumns, ", ")
+}
+func (r *fakeRows) Close() error { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestSQLStore(t *testing.T) {
+	_, db := newFakeDB(t)
+	store := &SQLStore{DB: db}
+
+	assert.Nil(t, store.CreateTable(context.Background()), "CreateTable error should be nil")
+	testStore(t, store)
+}
+
+func TestSQLStore_tableAndPlaceholders(t *testing.T) {
+	database, db := newFakeDB(t)
+	store := &SQLStore{DB: db, Table: "account_outbox", Placeholder: DollarPlaceholder}
+	ctx := context.Background()
+
+	errPut := store.Put(ctx, &Entry{Account: testAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc"), Status: StatusPending})
+	_, errPrune := store.Prune(ctx, time.Now())
+
+	assert.Nil(t, errPut, "Put error should be nil")
+	assert.Nil(t, errPrune, "Prune error should be nil")
+	assert.Equal(t, []string{
+		"INSERT INTO account_outbox (account_id, account, status, attempts, last_error, enqueued_at, updated_at) " +
+			"VALUES ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT (account_id) DO UPDATE SET " +
+			"account = excluded.account, status = excluded.status, attempts = excluded.attempts, " +
+			"last_error = excluded.last_error, enqueued_at = excluded.enqueued_at, updated_at = excluded.updated_at",
+		"DELETE FROM account_outbox WHERE status = $1 AND updated_at < $2",
+	}, database.queries, "queries incorrect")
+}
@@ -0,0 +1,56 @@
+package form3
+
+import (
+	"context"
+	"errors"
+)
+
+const defaultListEachPageSize = 100
+
+// ErrStopListing can be returned by the function of ListEach to stop paging without an error
+var ErrStopListing = errors.New("stop listing")
+
+// ListEach pages through the accounts matching the filter of listOpts, starting at its page number,
+// and calls fn with each of them in order. Pages hold 100 accounts unless listOpts sets a page size.
+func (s *AccountsService) ListEach(ctx context.Context, listOpts *ListOptions, fn func(account *Account) error, opts ...CallOption) error {
+	page := ListOptions{PageSize: defaultListEachPageSize}
+	if listOpts != nil {
+		page.PageNumber = listOpts.PageNumber
+		page.Filter = listOpts.Filter
+		page.Sort = listOpts.Sort
+		if listOpts.PageSize > 0 {
+			page.PageSize = listOpts.PageSize
+		}
+	}
+
+	for {
+		accounts, resp, err := s.List(ctx, &page, opts...)
+		if err != nil {
+			return err
+		}
+
+		for i := range accounts {
+			if err = fn(&accounts[i]); err != nil {
+				if errors.Is(err, ErrStopListing) {
+					return nil
+				}
+				return err
+			}
+		}
+
+		if !hasNextPage(resp, len(accounts), page.PageSize) {
+			return nil
+		}
+		page.PageNumber++
+	}
+}
+
+// hasNextPage follows the next link when the API sends links, otherwise a full page means there can be more
+func hasNextPage(resp *RestClientResponse, count int, pageSize int) bool {
+	if resp.Links != nil && resp.Links.Self != "" {
+		_, ok := resp.Links.NextPage()
+		return ok && count > 0
+	}
+
+	return count == pageSize
+}
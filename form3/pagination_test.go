@@ -0,0 +1,69 @@
+package form3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// mockedAccountsPages serves the ids as accounts split in pages, sending links when withLinks is true
+func mockedAccountsPages(t *testing.T, ids []string, withLinks bool) mockedHttpClientHandler {
+	return func(req *http.Request) (*http.Response, error) {
+		pageNumber, _ := strconv.Atoi(req.URL.Query().Get("page[number]"))
+		pageSize, _ := strconv.Atoi(req.URL.Query().Get("page[size]"))
+
+		var items []string
+		for i := pageNumber * pageSize; i < len(ids) && i < (pageNumber+1)*pageSize; i++ {
+			items = append(items, fmt.Sprintf(`{"id":"%s","type":"accounts","version":0}`, ids[i]))
+		}
+
+		links := ""
+		if withLinks {
+			links = fmt.Sprintf(`,"links":{"self":"/v1/organisation/accounts?page%%5Bnumber%%5D=%d"`, pageNumber)
+			if (pageNumber+1)*pageSize < len(ids) {
+				links += fmt.Sprintf(`,"next":"/v1/organisation/accounts?page%%5Bnumber%%5D=%d"`, pageNumber+1)
+			}
+			links += "}"
+		}
+
+		return mockedResponse(http.StatusOK, `{"data":[`+strings.Join(items, ",")+`]`+links+`}`, nil), nil
+	}
+}
+
+func TestAccountsService_ListEach(t *testing.T) {
+	for _, withLinks := range []bool{true, false} {
+		client, _ := NewRestClient(mockedAccountsPages(t, []string{"a1", "a2", "a3", "a4"}, withLinks), NewRestClientParams{BaseUrl: baseFakeUrl})
+
+		var ids []string
+		err := NewAccountsService(client).ListEach(context.Background(), &ListOptions{PageSize: 2}, func(account *Account) error {
+			ids = append(ids, account.ID)
+			return nil
+		})
+
+		assert.Nil(t, err, "Error should be nil")
+		assert.Equal(t, []string{"a1", "a2", "a3", "a4"}, ids, "Listed ids incorrect, links: %v", withLinks)
+	}
+}
+
+func TestAccountsService_ListEach_stop(t *testing.T) {
+	client, _ := NewRestClient(mockedAccountsPages(t, []string{"a1", "a2", "a3"}, false), NewRestClientParams{BaseUrl: baseFakeUrl})
+	service := NewAccountsService(client)
+
+	var ids []string
+	err := service.ListEach(context.Background(), nil, func(account *Account) error {
+		ids = append(ids, account.ID)
+		return ErrStopListing
+	})
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, []string{"a1"}, ids, "Listing should stop")
+
+	err = service.ListEach(context.Background(), nil, func(account *Account) error {
+		return errors.New("boom")
+	})
+	assert.Equal(t, "boom", err.Error(), "Error should be returned")
+}
@@ -0,0 +1,80 @@
+package form3
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimit caps the requests sent by a RestClient, retries included
+type RateLimit struct {
+	// RequestsPerSecond is the sustained rate of requests
+	RequestsPerSecond float64
+	// Burst is the number of requests that can be sent at once, 1 by default
+	Burst int
+}
+
+// rateLimiter is a token bucket filled at RequestsPerSecond up to Burst tokens
+type rateLimiter struct {
+	interval time.Duration
+	burst    float64
+	mu       sync.Mutex
+	tokens   float64
+	last     time.Time
+}
+
+func newRateLimiter(limit *RateLimit) *rateLimiter {
+	if limit == nil || limit.RequestsPerSecond <= 0 {
+		return nil
+	}
+
+	burst := float64(limit.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &rateLimiter{
+		interval: time.Duration(float64(time.Second) / limit.RequestsPerSecond),
+		burst:    burst,
+		tokens:   burst,
+		last:     time.Now(),
+	}
+}
+
+// wait blocks until a request can be sent or ctx is done
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += float64(now.Sub(l.last)) / float64(l.interval)
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	// the token is taken now, the caller waits until it would have been available
+	l.tokens--
+	delay := time.Duration(0)
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens * float64(l.interval))
+	}
+	l.mu.Unlock()
+
+	if delay == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
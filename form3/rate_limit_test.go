@@ -0,0 +1,59 @@
+package form3
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_wait(t *testing.T) {
+	limiter := newRateLimiter(&RateLimit{RequestsPerSecond: 100, Burst: 2})
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		assert.Nil(t, limiter.wait(ctx), "Error should be nil")
+	}
+
+	// the burst is sent at once, the other two wait 10ms each
+	assert.GreaterOrEqual(t, time.Since(start), 15*time.Millisecond, "Requests should be rate limited")
+}
+
+func TestRateLimiter_waitContextDone(t *testing.T) {
+	limiter := newRateLimiter(&RateLimit{RequestsPerSecond: 0.001})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	assert.Nil(t, limiter.wait(ctx), "First request should not wait")
+	assert.Equal(t, context.DeadlineExceeded, limiter.wait(ctx), "Second request should wait until the context is done")
+}
+
+func TestRateLimiter_disabled(t *testing.T) {
+	assert.Nil(t, newRateLimiter(nil), "Limiter should be nil")
+	assert.Nil(t, newRateLimiter(nil).wait(context.Background()), "Nil limiter should not wait")
+}
+
+type testLogger struct {
+	lines []string
+}
+
+func (l *testLogger) Printf(format string, v ...any) {
+	l.lines = append(l.lines, format)
+}
+
+func TestRestClient_Do_authTokenAndLogger(t *testing.T) {
+	mockedHttpClient := mockedHttpClientHandler(func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, "Bearer secret", req.Header.Get("Authorization"))
+
+		return mockedResponse(http.StatusOK, `{"data":{"id":"a1b2c3"}}`, nil), nil
+	})
+	logger := &testLogger{}
+	client, _ := NewRestClient(mockedHttpClient, NewRestClientParams{BaseUrl: baseFakeUrl, AuthToken: "secret", Logger: logger})
+
+	_, _, err := NewAccountsService(client).Get(context.Background(), "a1b2c3")
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Len(t, logger.lines, 1, "Request should be logged")
+}
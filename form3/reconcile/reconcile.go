@@ -0,0 +1,340 @@
+// Package reconcile compares a local copy of accounts with the accounts of the API,
+// reporting the differences and optionally repairing them.
+package reconcile
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"form3-interview-accountapi/form3"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Source provides the local account records
+type Source interface {
+	// Each calls fn with every local account
+	Each(ctx context.Context, fn func(account *form3.Account) error) error
+}
+
+// SliceSource is a Source over accounts held in memory
+type SliceSource []form3.Account
+
+func (s SliceSource) Each(ctx context.Context, fn func(account *form3.Account) error) error {
+	for i := range s {
+		if err := fn(&s[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Options configures a Reconciler
+type Options struct {
+	// Filter restricts the accounts listed from the API, as in form3.ListOptions
+	Filter map[string]string
+	// IgnoreFields are field paths left out of the comparison, e.g. attributes.status
+	IgnoreFields []string
+	// CreateMissing creates in the API the local accounts missing remotely
+	CreateMissing bool
+	// DeleteExtra deletes from the API the accounts missing locally
+	DeleteExtra bool
+	// UpdateMismatches updates the API accounts to the local attributes
+	UpdateMismatches bool
+	// DryRun reports the repair actions without sending them
+	DryRun bool
+}
+
+type ActionType string
+
+const (
+	ActionCreate ActionType = "create"
+	ActionDelete ActionType = "delete"
+	ActionUpdate ActionType = "update"
+)
+
+// Action is a repair applied, or planned in dry-run mode, to an account of the API
+type Action struct {
+	Type      ActionType
+	AccountID string
+	DryRun    bool
+	// Err is the error of the action, nil when it succeeded or was not sent
+	Err error
+}
+
+// FieldDiff is a field with different values. Values are nil when the field is not set
+type FieldDiff struct {
+	// Field is the path of the field using the API names, e.g. attributes.bank_id
+	Field  string
+	Local  any
+	Remote any
+}
+
+type Mismatch struct {
+	AccountID string
+	Local     *form3.Account
+	Remote    *form3.Account
+	Fields    []FieldDiff
+}
+
+// Report lists the differences found and the repair actions
+type Report struct {
+	MissingRemotely []form3.Account
+	MissingLocally  []form3.Account
+	Mismatches      []Mismatch
+	Matched         int
+	Actions         []Action
+}
+
+// InSync reports whether no difference was found
+func (r *Report) InSync() bool {
+	return len(r.MissingRemotely) == 0 && len(r.MissingLocally) == 0 && len(r.Mismatches) == 0
+}
+
+type Reconciler struct {
+	service form3.AccountsAPI
+	opts    Options
+}
+
+// New returns a Reconciler instance.
+func New(service form3.AccountsAPI, opts Options) *Reconciler {
+	return &Reconciler{service: service, opts: opts}
+}
+
+// Run compares the local accounts with the API and applies the repairs enabled in the options
+func (r *Reconciler) Run(ctx context.Context, source Source) (*Report, error) {
+	local := map[string]*form3.Account{}
+	var localOrder []string
+	err := source.Each(ctx, func(account *form3.Account) error {
+		if _, ok := local[account.ID]; !ok {
+			localOrder = append(localOrder, account.ID)
+		}
+		local[account.ID] = account
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading local accounts: %w", err)
+	}
+
+	report := &Report{}
+	remoteSeen := map[string]bool{}
+	err = r.service.ListEach(ctx, &form3.ListOptions{Filter: r.opts.Filter}, func(remote *form3.Account) error {
+		remoteSeen[remote.ID] = true
+
+		localAccount, ok := local[remote.ID]
+		if !ok {
+			report.MissingLocally = append(report.MissingLocally, *remote)
+			return nil
+		}
+
+		fields, err := Diff(localAccount, remote, r.opts.IgnoreFields...)
+		if err != nil {
+			return err
+		}
+		if len(fields) == 0 {
+			report.Matched++
+			return nil
+		}
+		report.Mismatches = append(report.Mismatches, Mismatch{
+			AccountID: remote.ID,
+			Local:     localAccount,
+			Remote:    remote,
+			Fields:    fields,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing remote accounts: %w", err)
+	}
+
+	for _, id := range localOrder {
+		if !remoteSeen[id] {
+			report.MissingRemotely = append(report.MissingRemotely, *local[id])
+		}
+	}
+
+	r.repair(ctx, report)
+
+	return report, nil
+}
+
+func (r *Reconciler) repair(ctx context.Context, report *Report) {
+	if r.opts.CreateMissing {
+		for i := range report.MissingRemotely {
+			account := &report.MissingRemotely[i]
+			report.Actions = append(report.Actions, r.apply(ActionCreate, account.ID, func() error {
+				_, _, err := r.service.Create(ctx, account)
+				return err
+			}))
+		}
+	}
+
+	if r.opts.DeleteExtra {
+		for _, account := range report.MissingLocally {
+			report.Actions = append(report.Actions, r.apply(ActionDelete, account.ID, func() error {
+				_, err := r.service.DeleteLatest(ctx, account.ID, form3.WithNotFoundAsSuccess())
+				return err
+			}))
+		}
+	}
+
+	if r.opts.UpdateMismatches {
+		for _, mismatch := range report.Mismatches {
+			report.Actions = append(report.Actions, r.apply(ActionUpdate, mismatch.AccountID, func() error {
+				patch, status, err := attributesPatch(mismatch)
+				if err != nil {
+					return err
+				}
+				if patch != nil {
+					if _, _, err = r.service.Update(ctx, mismatch.AccountID, mismatch.Remote.Version, patch); err != nil {
+						return err
+					}
+				}
+				if status != "" {
+					_, _, err = r.service.Transition(ctx, mismatch.AccountID, status)
+				}
+				return err
+			}))
+		}
+	}
+}
+
+func (r *Reconciler) apply(actionType ActionType, id string, send func() error) Action {
+	action := Action{Type: actionType, AccountID: id, DryRun: r.opts.DryRun}
+	if !r.opts.DryRun {
+		action.Err = send()
+	}
+
+	return action
+}
+
+// ignoredByDefault are the fields managed by the API, always different between copies
+var ignoredByDefault = []string{"version", "created_on", "modified_on"}
+
+// Diff returns the fields with different values in two accounts, compared by their JSON encoding
+func Diff(local *form3.Account, remote *form3.Account, ignoreFields ...string) ([]FieldDiff, error) {
+	localFields, err := flatten(local)
+	if err != nil {
+		return nil, err
+	}
+	remoteFields, err := flatten(remote)
+	if err != nil {
+		return nil, err
+	}
+
+	ignored := map[string]bool{}
+	for _, field := range append(ignoredByDefault, ignoreFields...) {
+		ignored[field] = true
+	}
+
+	names := map[string]bool{}
+	for name := range localFields {
+		names[name] = true
+	}
+	for name := range remoteFields {
+		names[name] = true
+	}
+
+	var diffs []FieldDiff
+	for name := range names {
+		if ignored[name] || reflect.DeepEqual(localFields[name], remoteFields[name]) {
+			continue
+		}
+		diffs = append(diffs, FieldDiff{Field: name, Local: localFields[name], Remote: remoteFields[name]})
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Field < diffs[j].Field })
+
+	return diffs, nil
+}
+
+// flatten returns the JSON members of the account by path. Arrays are compared as a whole value
+func flatten(account *form3.Account) (map[string]any, error) {
+	data, err := json.Marshal(account)
+	if err != nil {
+		return nil, err
+	}
+
+	var decoded map[string]any
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+
+	fields := map[string]any{}
+	flattenInto(fields, "", decoded)
+
+	return fields, nil
+}
+
+func flattenInto(fields map[string]any, prefix string, value map[string]any) {
+	for name, member := range value {
+		if object, ok := member.(map[string]any); ok {
+			flattenInto(fields, prefix+name+".", object)
+			continue
+		}
+		fields[prefix+name] = member
+	}
+}
+
+const attributesPrefix = "attributes."
+
+// attributesPatch builds the update setting the mismatched attributes to their local values, nil if only
+// the status is mismatched. The local status is returned apart, it is changed with a transition.
+func attributesPatch(mismatch Mismatch) (*form3.AccountAttributesPatch, form3.AccountStatus, error) {
+	var status form3.AccountStatus
+	members := map[string]json.RawMessage{}
+	for _, field := range mismatch.Fields {
+		name, ok := strings.CutPrefix(field.Field, attributesPrefix)
+		if !ok || name == "" {
+			return nil, "", fmt.Errorf("field %s cannot be updated", field.Field)
+		}
+		if name == "status" {
+			if mismatch.Local.Attributes != nil {
+				status = mismatch.Local.Attributes.Status
+			}
+			continue
+		}
+
+		// nested attributes are updated as a whole
+		name, _, _ = strings.Cut(name, ".")
+		if _, ok = members[name]; ok {
+			continue
+		}
+
+		var localValue any
+		if mismatch.Local.Attributes != nil {
+			attributes, err := json.Marshal(mismatch.Local.Attributes)
+			if err != nil {
+				return nil, "", err
+			}
+			var decoded map[string]any
+			if err = json.Unmarshal(attributes, &decoded); err != nil {
+				return nil, "", err
+			}
+			localValue = decoded[name]
+		}
+
+		value, err := json.Marshal(localValue)
+		if err != nil {
+			return nil, "", err
+		}
+		members[name] = value
+	}
+
+	if len(members) == 0 {
+		return nil, status, nil
+	}
+
+	data, err := json.Marshal(members)
+	if err != nil {
+		return nil, "", err
+	}
+
+	patch := &form3.AccountAttributesPatch{}
+	if err = json.Unmarshal(data, patch); err != nil {
+		return nil, "", err
+	}
+
+	return patch, status, nil
+}
@@ -0,0 +1,169 @@
+package reconcile
+
+import (
+	"context"
+	"form3-interview-accountapi/form3"
+	"form3-interview-accountapi/form3/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newFakeAPI(t *testing.T, accounts ...form3.Account) (*testsupport.FakeAPI, *form3.AccountsService) {
+	api := testsupport.NewFakeAPI(t, testsupport.FakeAPIOptions{}, accounts...)
+
+	return api, api.Service()
+}
+
+func testAccount(id string, bankID string) form3.Account {
+	return form3.Account{
+		ID:             id,
+		OrganisationID: "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c",
+		Type:           form3.AcctTypeAccounts,
+		Attributes: &form3.AccountAttributes{
+			Country:      form3.CountryCodeBelgium,
+			BankID:       bankID,
+			Name:         []string{"Samantha Holder"},
+			BaseCurrency: form3.BaseCurrencyEur,
+		},
+	}
+}
+
+func TestReconciler_Run_report(t *testing.T) {
+	_, service := newFakeAPI(t,
+		testAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "400300"),
+		testAccount("b7a1c6f2-1e2c-4bde-8d1b-3c3f1f0e9a11", "400300"),
+		testAccount("c0f5b2d4-6a9e-4f4b-9b8e-6e7a2c1d3f22", "400300"),
+	)
+
+	local := SliceSource{
+		testAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "400300"),
+		testAccount("b7a1c6f2-1e2c-4bde-8d1b-3c3f1f0e9a11", "400301"),
+		testAccount("d9e8f7a6-5b4c-4d3e-8f2a-1b0c9d8e7f33", "400300"),
+	}
+
+	report, err := New(service, Options{}).Run(context.Background(), local)
+
+	assert.Nil(t, err, "Run error should be nil")
+	assert.False(t, report.InSync(), "report should not be in sync")
+	assert.Equal(t, 1, report.Matched, "Matched incorrect")
+	assert.Len(t, report.MissingRemotely, 1, "MissingRemotely length incorrect")
+	assert.Equal(t, "d9e8f7a6-5b4c-4d3e-8f2a-1b0c9d8e7f33", report.MissingRemotely[0].ID, "MissingRemotely ID incorrect")
+	assert.Len(t, report.MissingLocally, 1, "MissingLocally length incorrect")
+	assert.Equal(t, "c0f5b2d4-6a9e-4f4b-9b8e-6e7a2c1d3f22", report.MissingLocally[0].ID, "MissingLocally ID incorrect")
+	assert.Len(t, report.Mismatches, 1, "Mismatches length incorrect")
+	assert.Equal(t, []FieldDiff{{Field: "attributes.bank_id", Local: "400301", Remote: "400300"}}, report.Mismatches[0].Fields, "Fields incorrect")
+	assert.Empty(t, report.Actions, "Actions should be empty")
+}
+
+func TestReconciler_Run_repair(t *testing.T) {
+	api, service := newFakeAPI(t,
+		testAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "400300"),
+		testAccount("c0f5b2d4-6a9e-4f4b-9b8e-6e7a2c1d3f22", "400300"),
+	)
+
+	local := SliceSource{
+		testAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "400301"),
+		testAccount("d9e8f7a6-5b4c-4d3e-8f2a-1b0c9d8e7f33", "400300"),
+	}
+	reconciler := New(service, Options{CreateMissing: true, DeleteExtra: true, UpdateMismatches: true})
+
+	report, err := reconciler.Run(context.Background(), local)
+
+	assert.Nil(t, err, "Run error should be nil")
+	assert.Equal(t, []Action{
+		{Type: ActionCreate, AccountID: "d9e8f7a6-5b4c-4d3e-8f2a-1b0c9d8e7f33"},
+		{Type: ActionDelete, AccountID: "c0f5b2d4-6a9e-4f4b-9b8e-6e7a2c1d3f22"},
+		{Type: ActionUpdate, AccountID: "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc"},
+	}, report.Actions, "Actions incorrect")
+
+	report, err = reconciler.Run(context.Background(), local)
+
+	assert.Nil(t, err, "Run error should be nil")
+	assert.True(t, report.InSync(), "report should be in sync after repair")
+	assert.Equal(t, 2, report.Matched, "Matched incorrect")
+	assert.Len(t, api.Requests(), 3, "requests length incorrect")
+}
+
+func TestReconciler_Run_updateStatus(t *testing.T) {
+	remote := testAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "400300")
+	remote.Attributes.Status = form3.AcctStatusPending
+	api, service := newFakeAPI(t, remote)
+
+	local := testAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "400301")
+	local.Attributes.Status = form3.AcctStatusConfirmed
+	reconciler := New(service, Options{UpdateMismatches: true})
+
+	report, err := reconciler.Run(context.Background(), SliceSource{local})
+
+	assert.Nil(t, err, "Run error should be nil")
+	assert.Equal(t, []Action{{Type: ActionUpdate, AccountID: "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc"}}, report.Actions, "Actions incorrect")
+	assert.Equal(t, []string{"PATCH ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "PATCH ad27e265-9605-4b4b-a0e5-3003ea9cc4dc"}, api.Requests(), "requests incorrect")
+
+	report, err = reconciler.Run(context.Background(), SliceSource{local})
+
+	assert.Nil(t, err, "Run error should be nil")
+	assert.True(t, report.InSync(), "report should be in sync after the update and transition")
+}
+
+func TestReconciler_Run_dryRun(t *testing.T) {
+	api, service := newFakeAPI(t,
+		testAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "400300"),
+		testAccount("c0f5b2d4-6a9e-4f4b-9b8e-6e7a2c1d3f22", "400300"),
+	)
+
+	local := SliceSource{
+		testAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "400301"),
+		testAccount("d9e8f7a6-5b4c-4d3e-8f2a-1b0c9d8e7f33", "400300"),
+	}
+	reconciler := New(service, Options{CreateMissing: true, DeleteExtra: true, UpdateMismatches: true, DryRun: true})
+
+	report, err := reconciler.Run(context.Background(), local)
+
+	assert.Nil(t, err, "Run error should be nil")
+	assert.Len(t, report.Actions, 3, "Actions length incorrect")
+	for _, action := range report.Actions {
+		assert.True(t, action.DryRun, "action should be a dry run")
+	}
+	assert.Empty(t, api.Requests(), "no request should be sent")
+}
+
+func TestReconciler_Run_ignoreFields(t *testing.T) {
+	_, service := newFakeAPI(t, testAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "400300"))
+
+	local := SliceSource{testAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "400301")}
+
+	report, err := New(service, Options{IgnoreFields: []string{"attributes.bank_id"}}).Run(context.Background(), local)
+
+	assert.Nil(t, err, "Run error should be nil")
+	assert.True(t, report.InSync(), "report should be in sync")
+}
+
+func TestReconciler_Run_updateNotAllowed(t *testing.T) {
+	remote := testAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "400300")
+	_, service := newFakeAPI(t, remote)
+
+	local := testAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "400300")
+	local.OrganisationID = "0d209d7f-d07a-4719-8f1d-3e2e2c2b6f44"
+
+	report, err := New(service, Options{UpdateMismatches: true}).Run(context.Background(), SliceSource{local})
+
+	assert.Nil(t, err, "Run error should be nil")
+	assert.Len(t, report.Actions, 1, "Actions length incorrect")
+	assert.EqualError(t, report.Actions[0].Err, "field organisation_id cannot be updated", "action error incorrect")
+}
+
+func TestDiff(t *testing.T) {
+	local := testAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "400300")
+	remote := testAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "400300")
+	remote.Version = 3
+	remote.Attributes.Name = []string{"Samantha Holder", "S Holder"}
+	remote.Attributes.Bic = "NWBKGB22"
+
+	diffs, err := Diff(&local, &remote)
+
+	assert.Nil(t, err, "Diff error should be nil")
+	assert.Equal(t, []FieldDiff{
+		{Field: "attributes.bic", Local: nil, Remote: "NWBKGB22"},
+		{Field: "attributes.name", Local: []any{"Samantha Holder"}, Remote: []any{"Samantha Holder", "S Holder"}},
+	}, diffs, "diffs incorrect")
+}
@@ -9,23 +9,65 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"time"
 )
 
+// defaultMaxResponseSize is the maximum size of a response body read by Do if not configured
+const defaultMaxResponseSize int64 = 10 << 20
+
 type NewRestClientParams struct {
 	BaseUrl string
+	// MaxResponseSize limits the bytes read from a response body, 10MB by default
+	MaxResponseSize int64
+	// DecodeMode sets how unknown members of the response data are handled, ignored by default
+	DecodeMode DecodeMode
+	// RetryPolicy sets how requests are retried, they are not retried by default.
+	// It can be overridden per call with WithRetryPolicy
+	RetryPolicy *RetryPolicy
+	// Transport configures the transport of the default http.Client, ignored when a httpClient is provided
+	Transport *TransportConfig
+	// Timeout limits the duration of each call unless overridden with WithTimeout, no limit by default
+	Timeout time.Duration
+	// RateLimit caps the requests sent, no limit by default
+	RateLimit *RateLimit
+	// AuthToken is sent as a bearer token in the Authorization header of every request
+	AuthToken string
+	// Logger logs every request sent and its outcome when set
+	Logger Logger
+	// CoalesceGets makes concurrent identical GET requests share a single request and response.
+	// The options of the first caller, such as the retry policy, apply to the shared request
+	CoalesceGets bool
+	// Hedging sends a second request for slow GET requests when set, see HedgePolicy
+	Hedging *HedgePolicy
+}
+
+// Logger is the logging interface used by RestClient, satisfied by *log.Logger
+type Logger interface {
+	Printf(format string, v ...any)
 }
 
 type body struct {
 	Data         any    `json:"data"`
+	Links        *Links `json:"links,omitempty"`
+	Meta         *Meta  `json:"meta,omitempty"`
 	ErrorMessage string `json:"error_message,omitempty"`
 }
 
 // NewRestClient returns a RestClient instance.
-// If a httpClient is not provided, a default http.Client will be assigned
+// If a httpClient is not provided, a http.Client using a transport built from params.Transport will be assigned
 func NewRestClient(httpClient HttpClient, params NewRestClientParams) (*RestClient, error) {
 	if httpClient == nil {
-		httpClient = &http.Client{}
+		transportConfig := TransportConfig{}
+		if params.Transport != nil {
+			transportConfig = *params.Transport
+		}
+		transport, err := NewTransport(transportConfig)
+		if err != nil {
+			return nil, err
+		}
+		httpClient = &http.Client{Transport: transport}
 	}
 
 	baseUrl, err := url.ParseRequestURI(params.BaseUrl)
@@ -33,32 +75,64 @@ func NewRestClient(httpClient HttpClient, params NewRestClientParams) (*RestClie
 		return nil, err
 	}
 
+	maxResponseSize := params.MaxResponseSize
+	if maxResponseSize <= 0 {
+		maxResponseSize = defaultMaxResponseSize
+	}
+
 	restClient := &RestClient{
-		httpClient: httpClient,
-		baseURL:    baseUrl,
+		httpClient:      httpClient,
+		baseURL:         baseUrl,
+		maxResponseSize: maxResponseSize,
+		decodeMode:      params.DecodeMode,
+		retryPolicy:     params.RetryPolicy,
+		timeout:         params.Timeout,
+		rateLimiter:     newRateLimiter(params.RateLimit),
+		authToken:       params.AuthToken,
+		logger:          params.Logger,
+		coalescer:       newCoalescer(params.CoalesceGets),
+		hedger:          newHedger(params.Hedging),
 	}
 
 	return restClient, nil
 }
 
 // GetRequest this method returns a GET request ready to send to the api.
-func (c *RestClient) GetRequest(path string) (*RestClientRequest, error) {
-	return c.newRequest("GET", path, nil)
+func (c *RestClient) GetRequest(path string, opts ...CallOption) (*RestClientRequest, error) {
+	return c.newRequest("GET", path, nil, opts)
 }
 
 // PostRequest this method returns a POST request ready to send to the api.
 // The data payload is wraps in a correct body format accepted by the api
-func (c *RestClient) PostRequest(path string, data any) (*RestClientRequest, error) {
+func (c *RestClient) PostRequest(path string, data any, opts ...CallOption) (*RestClientRequest, error) {
 	body := body{Data: data}
-	return c.newRequest("POST", path, body)
+	return c.newRequest("POST", path, body, opts)
+}
+
+// PatchRequest this method returns a PATCH request ready to send to the api.
+// The data payload is wraps in a correct body format accepted by the api
+func (c *RestClient) PatchRequest(path string, data any, opts ...CallOption) (*RestClientRequest, error) {
+	body := body{Data: data}
+	return c.newRequest("PATCH", path, body, opts)
 }
 
 // DeleteRequest this method returns a DELETE request ready to send to the api.
-func (c *RestClient) DeleteRequest(path string) (*RestClientRequest, error) {
-	return c.newRequest("DELETE", path, nil)
+func (c *RestClient) DeleteRequest(path string, opts ...CallOption) (*RestClientRequest, error) {
+	return c.newRequest("DELETE", path, nil, opts)
+}
+
+// LinkRequest this method returns a GET request to a link of a previous response, e.g. Links.Next.
+// Links are resolved against the host of the base URL.
+func (c *RestClient) LinkRequest(link string, opts ...CallOption) (*RestClientRequest, error) {
+	linkURL, err := url.Parse(link)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.newURLRequest("GET", c.baseURL.ResolveReference(linkURL), nil, opts)
 }
 
-func (c *RestClient) newRequest(method string, path string, body any) (*RestClientRequest, error) {
+func (c *RestClient) newRequest(method string, path string, body any, opts []CallOption) (*RestClientRequest, error) {
 	targetURL, err := url.Parse(
 		fmt.Sprintf("%s/%s", c.baseURL.String(), strings.TrimPrefix(path, "/")),
 	)
@@ -66,9 +140,25 @@ func (c *RestClient) newRequest(method string, path string, body any) (*RestClie
 		return nil, err
 	}
 
+	return c.newURLRequest(method, targetURL, body, opts)
+}
+
+func (c *RestClient) newURLRequest(method string, targetURL *url.URL, body any, opts []CallOption) (*RestClientRequest, error) {
+	config := newCallConfig(opts)
+	if len(config.query) > 0 {
+		query := targetURL.Query()
+		for key, values := range config.query {
+			query[key] = append(query[key], values...)
+		}
+		targetURL.RawQuery = query.Encode()
+	}
+
 	var payload io.Reader
 	if body != nil {
-		dataJson, _ := json.Marshal(body)
+		dataJson, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
 		payload = bytes.NewBuffer(dataJson)
 	}
 
@@ -78,53 +168,122 @@ func (c *RestClient) newRequest(method string, path string, body any) (*RestClie
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	if c.authToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.authToken)
+	}
+	for key, values := range config.header {
+		req.Header[key] = values
+	}
 
 	return &RestClientRequest{req}, nil
 }
 
 // Do send the request to the API and unwrap the response data in the v target if it is sent as a parameter.
+// The response body is decoded in a single pass straight into v and must not exceed the max response size.
+// The timeout, retry policy and response hooks of opts are applied, other options apply on request creation.
 // ctx must not be nil
-func (c *RestClient) Do(ctx context.Context, req *http.Request, v any) (*RestClientResponse, error) {
+func (c *RestClient) Do(ctx context.Context, req *http.Request, v any, opts ...CallOption) (*RestClientResponse, error) {
 	if ctx == nil {
 		return nil, errors.New("context must be non-nil")
 	}
 
-	req = req.WithContext(ctx)
-	resp, err := c.httpClient.Do(req)
+	config := newCallConfig(opts)
+	timeout := c.timeout
+	if config.timeout > 0 {
+		timeout = config.timeout
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	retryPolicy := c.retryPolicy
+	if config.retryPolicy != nil {
+		retryPolicy = config.retryPolicy
+	}
+
+	var resp *http.Response
+	var err error
+	if c.coalescer != nil && req.Method == http.MethodGet {
+		resp, err = c.sendCoalesced(ctx, req, retryPolicy)
+	} else {
+		resp, err = c.roundTrip(ctx, req, retryPolicy)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	response := &RestClientResponse{resp}
+	response, err := c.decode(resp, v)
+	for _, hook := range config.responseHooks {
+		hook(response, err)
+	}
+
+	return response, err
+}
+
+// decode unwraps the response data in the v target
+func (c *RestClient) decode(resp *http.Response, v any) (*RestClientResponse, error) {
+	response := &RestClientResponse{Response: resp}
 
 	defer resp.Body.Close()
 
-	respData, err := io.ReadAll(resp.Body)
-	if len(respData) == 0 { // means is a http.noBody response
+	// the envelope data is decoded into v directly, when v is nil it is kept raw instead of built as a map.
+	// Other decode modes need the raw data to look for unknown members.
+	var data json.RawMessage
+	body := &body{Data: v}
+	if v == nil || c.decodeMode != DecodeModeDefault {
+		body.Data = &data
+	}
+
+	reader := http.MaxBytesReader(nil, resp.Body, c.maxResponseSize)
+	err := json.NewDecoder(reader).Decode(body)
+	if err == io.EOF { // means is a http.noBody response
 		return response, nil
 	}
 	if err != nil {
 		return response, err
 	}
+	// drain what is left so the connection can be reused
+	_, _ = io.Copy(io.Discard, reader)
 
-	body := &body{}
-	if err = json.Unmarshal(respData, body); err != nil {
-		return response, err
-	}
+	response.Links = body.Links
+	response.Meta = body.Meta
 	if body.ErrorMessage != "" {
 		return response, errors.New(body.ErrorMessage)
 	}
 
-	if v != nil {
-		bodyDataCoded, err := json.Marshal(body.Data)
-		if err != nil {
-			return response, err
-		}
-		err = json.Unmarshal(bodyDataCoded, v)
-		if err != nil {
+	if v != nil && c.decodeMode != DecodeModeDefault && len(data) > 0 {
+		if err = decodeData(data, v, c.decodeMode); err != nil {
 			return response, err
 		}
 	}
 
 	return response, nil
 }
+
+// listPath appends the paging and filter query parameters of opts to path
+func listPath(path string, opts *ListOptions) string {
+	if opts == nil {
+		return path
+	}
+
+	query := url.Values{}
+	if opts.PageNumber > 0 {
+		query.Set("page[number]", strconv.Itoa(opts.PageNumber))
+	}
+	if opts.PageSize > 0 {
+		query.Set("page[size]", strconv.Itoa(opts.PageSize))
+	}
+	for key, value := range opts.Filter {
+		query.Set(fmt.Sprintf("filter[%s]", key), value)
+	}
+	if opts.Sort != "" {
+		query.Set("sort", opts.Sort)
+	}
+	if len(query) == 0 {
+		return path
+	}
+
+	return fmt.Sprintf("%s?%s", path, query.Encode())
+}
@@ -3,6 +3,7 @@ package form3
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"io"
@@ -81,6 +82,16 @@ func TestRestClient_POST(t *testing.T) {
 	testRequest(t, req.Request, testRequestExpected{method: "POST", path: testPath, body: `{"data":{"id":"1","name":"cristian"}}`})
 }
 
+func TestRestClient_PATCH(t *testing.T) {
+	c, _ := NewRestClient(nil, NewRestClientParams{BaseUrl: baseFakeUrl})
+	testPath := "test-patch-path/123456789"
+	testData := struct {
+		Version int `json:"version"`
+	}{Version: 2}
+	req, _ := c.PatchRequest(testPath, testData)
+	testRequest(t, req.Request, testRequestExpected{method: "PATCH", path: testPath, body: `{"data":{"version":2}}`})
+}
+
 func TestRestClient_DELETE(t *testing.T) {
 	c, _ := NewRestClient(nil, NewRestClientParams{BaseUrl: baseFakeUrl})
 	testPath := "/test-delete-path/123456789"
@@ -161,3 +172,84 @@ func TestRestClient_Do_errorResponse(t *testing.T) {
 	assert.Equal(t, "record 123 does not exist", err.Error(), "Error message expected")
 	assert.Empty(t, 0, v, "Target response object should be empty")
 }
+
+func TestRestClient_Do_responseTooLarge(t *testing.T) {
+	mockedHttpClient := mockedHttpClientHandler(func(req *http.Request) (*http.Response, error) {
+		return mockedResponse(http.StatusOK, `{ "data": { "a": "0123456789" } }`, nil), nil
+	})
+	client, err := NewRestClient(mockedHttpClient, NewRestClientParams{BaseUrl: baseFakeUrl, MaxResponseSize: 16})
+	if err != nil {
+		t.Fatalf("Error creting RestClient: %v", err)
+	}
+
+	req, _ := client.GetRequest("foo")
+	resp, err := client.Do(context.Background(), req.Request, &struct{}{})
+
+	assert.NotNil(t, resp, "RestClient response should be not nil")
+	var maxBytesError *http.MaxBytesError
+	assert.ErrorAs(t, err, &maxBytesError, "RestClient error should be a MaxBytesError")
+}
+
+func TestRestClient_Do_withoutTarget(t *testing.T) {
+	mockedHttpClient := mockedHttpClientHandler(func(req *http.Request) (*http.Response, error) {
+		return mockedResponse(http.StatusOK, `{ "data": { "a": 1 } }`, nil), nil
+	})
+	client, _ := NewRestClient(mockedHttpClient, NewRestClientParams{BaseUrl: baseFakeUrl})
+
+	req, _ := client.GetRequest("foo")
+	resp, err := client.Do(context.Background(), req.Request, nil)
+
+	assert.Nil(t, err, "RestClient Error is not nil")
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "Error in response status expected")
+}
+
+// benchmarkListBody returns a list response with n accounts, as returned by AccountsService.List
+func benchmarkListBody(n int) string {
+	account := `{"id":"ad27e265-9605-4b4b-a0e5-3003ea9cc4dc","organisation_id":"eb0bd6f5-c3f5-44b2-b677-acd23cdde73c",` +
+		`"type":"accounts","version":0,"created_on":"2023-03-01T10:00:00.000Z","modified_on":"2023-03-01T10:00:00.000Z",` +
+		`"attributes":{"account_classification":"Personal","alternative_names":["foo","bar"],"bank_id":"400300",` +
+		`"bank_id_code":"GBDSC","base_currency":"GBP","bic":"NWBKGB22","country":"GB","name":["cristian","pelegrin"],"status":"confirmed"}}`
+
+	return `{"data":[` + strings.TrimSuffix(strings.Repeat(account+",", n), ",") + `]}`
+}
+
+func BenchmarkRestClient_Do_list(b *testing.B) {
+	listBody := benchmarkListBody(100)
+	mockedHttpClient := mockedHttpClientHandler(func(req *http.Request) (*http.Response, error) {
+		return mockedResponse(http.StatusOK, listBody, nil), nil
+	})
+	client, _ := NewRestClient(mockedHttpClient, NewRestClientParams{BaseUrl: baseFakeUrl})
+	req, _ := client.GetRequest(accountsBasePath)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var accounts []Account
+		if _, err := client.Do(ctx, req.Request, &accounts); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkDecode_threePasses measures the previous decoding of Do, which read the whole body,
+// unmarshalled it into a map and marshalled the data again to unmarshal it into the target.
+// Compare it with BenchmarkRestClient_Do_list to see the allocations saved.
+func BenchmarkDecode_threePasses(b *testing.B) {
+	listBody := benchmarkListBody(100)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		respData, _ := io.ReadAll(mockedResponse(http.StatusOK, listBody, nil).Body)
+		body := &body{}
+		if err := json.Unmarshal(respData, body); err != nil {
+			b.Fatal(err)
+		}
+		bodyDataCoded, _ := json.Marshal(body.Data)
+		var accounts []Account
+		if err := json.Unmarshal(bodyDataCoded, &accounts); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package form3
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy sets how many times and how often a request is retried
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts, the first one included. Less than 2 disables retries
+	MaxAttempts int
+	// Backoff is the wait before the first retry, doubled after each one up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// RetryOn decides if an attempt is retried. By default network errors, 429 and 5xx responses
+	// are retried for idempotent methods and requests with an Idempotency-Key
+	RetryOn func(req *http.Request, resp *http.Response, err error) bool
+}
+
+func (p *RetryPolicy) shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if p.RetryOn != nil {
+		return p.RetryOn(req, resp, err)
+	}
+
+	return DefaultRetryOn(req, resp, err)
+}
+
+func (p *RetryPolicy) backoff(retry int) time.Duration {
+	wait := p.Backoff
+	for i := 1; i < retry && (p.MaxBackoff <= 0 || wait < p.MaxBackoff); i++ {
+		wait *= 2
+	}
+	if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+
+	return wait
+}
+
+// DefaultRetryOn retries network errors, 429 and 5xx responses of requests safe to send again
+func DefaultRetryOn(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+	default:
+		if req.Header.Get(idempotencyKeyHeader) == "" {
+			return false
+		}
+	}
+
+	if err != nil {
+		return true
+	}
+
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+}
+
+// send sends the request through the http client retrying it as the policy says
+func (c *RestClient) send(ctx context.Context, req *http.Request, policy *RetryPolicy) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		attemptReq := req.WithContext(ctx)
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq.Body = body
+		}
+
+		if err := c.rateLimiter.wait(ctx); err != nil {
+			return nil, err
+		}
+
+		start := time.Now()
+		resp, err := c.httpClient.Do(attemptReq)
+		c.logAttempt(attemptReq, resp, err, time.Since(start))
+		if policy == nil || attempt >= policy.MaxAttempts || !canResend(req) ||
+			!policy.shouldRetry(attemptReq, resp, err) {
+			return resp, err
+		}
+
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(policy.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// canResend reports if the request body can be sent again
+func canResend(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+func (c *RestClient) logAttempt(req *http.Request, resp *http.Response, err error, duration time.Duration) {
+	if c.logger == nil {
+		return
+	}
+
+	if err != nil {
+		c.logger.Printf("%s %s failed after %s: %v", req.Method, req.URL.Redacted(), duration, err)
+		return
+	}
+	c.logger.Printf("%s %s %d in %s", req.Method, req.URL.Redacted(), resp.StatusCode, duration)
+}
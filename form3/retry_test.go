@@ -0,0 +1,102 @@
+package form3
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRestClient_Do_retries(t *testing.T) {
+	attempts := 0
+	mockedHttpClient := mockedHttpClientHandler(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		if attempts == 1 {
+			return nil, errors.New("connection reset")
+		}
+		if attempts == 2 {
+			return mockedResponse(http.StatusServiceUnavailable, `{"error_message":"unavailable"}`, nil), nil
+		}
+		return mockedResponse(http.StatusOK, `{"data":{"id":"a1b2c3"}}`, nil), nil
+	})
+	client, _ := NewRestClient(mockedHttpClient, NewRestClientParams{
+		BaseUrl:     baseFakeUrl,
+		RetryPolicy: &RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond},
+	})
+
+	account, resp, err := NewAccountsService(client).Get(context.Background(), "a1b2c3")
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "Response code incorrect")
+	assert.Equal(t, "a1b2c3", account.ID, "account.ID incorrect")
+	assert.Equal(t, 3, attempts, "Attempts incorrect")
+}
+
+func TestRestClient_Do_retryPolicyOverride(t *testing.T) {
+	attempts := 0
+	mockedHttpClient := mockedHttpClientHandler(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		return mockedResponse(http.StatusInternalServerError, `{"error_message":"boom"}`, nil), nil
+	})
+	client, _ := NewRestClient(mockedHttpClient, NewRestClientParams{
+		BaseUrl:     baseFakeUrl,
+		RetryPolicy: &RetryPolicy{MaxAttempts: 3},
+	})
+	service := NewAccountsService(client)
+
+	_, resp, err := service.Get(context.Background(), "a1b2c3", WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+
+	assert.Equal(t, "boom", err.Error(), "Error message expected")
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode, "Response code incorrect")
+	assert.Equal(t, 1, attempts, "Attempts incorrect")
+}
+
+func TestRestClient_Do_retriesPostOnlyWithIdempotencyKey(t *testing.T) {
+	var bodies []string
+	mockedHttpClient := mockedHttpClientHandler(func(req *http.Request) (*http.Response, error) {
+		body, _ := io.ReadAll(req.Body)
+		bodies = append(bodies, string(body))
+		return mockedResponse(http.StatusBadGateway, "", nil), nil
+	})
+	client, _ := NewRestClient(mockedHttpClient, NewRestClientParams{
+		BaseUrl:     baseFakeUrl,
+		RetryPolicy: &RetryPolicy{MaxAttempts: 2},
+	})
+	service := NewAccountsService(client)
+
+	_, _, _ = service.Create(context.Background(), &Account{ID: "a1"})
+	assert.Len(t, bodies, 1, "POST without Idempotency-Key should not be retried")
+
+	bodies = nil
+	_, _, _ = service.Create(context.Background(), &Account{ID: "a1"}, WithIdempotencyKey("key-1"))
+	assert.Len(t, bodies, 2, "POST with Idempotency-Key should be retried")
+	assert.Equal(t, bodies[0], bodies[1], "Retried request should send the same body")
+}
+
+func TestRestClient_Do_retryStopsOnContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	mockedHttpClient := mockedHttpClientHandler(func(req *http.Request) (*http.Response, error) {
+		cancel()
+		return mockedResponse(http.StatusServiceUnavailable, "", nil), nil
+	})
+	client, _ := NewRestClient(mockedHttpClient, NewRestClientParams{
+		BaseUrl:     baseFakeUrl,
+		RetryPolicy: &RetryPolicy{MaxAttempts: 5, Backoff: time.Hour},
+	})
+
+	_, resp, err := NewAccountsService(client).Get(ctx, "a1b2c3")
+
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode, "Response code incorrect")
+	assert.Nil(t, err, "Error should be nil")
+}
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	policy := &RetryPolicy{Backoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+
+	assert.Equal(t, 100*time.Millisecond, policy.backoff(1), "First backoff incorrect")
+	assert.Equal(t, 200*time.Millisecond, policy.backoff(2), "Second backoff incorrect")
+	assert.Equal(t, 300*time.Millisecond, policy.backoff(3), "Backoff should be capped")
+}
@@ -0,0 +1,60 @@
+package form3
+
+import (
+	"context"
+	"fmt"
+)
+
+// accountTransitions lists the statuses an account can move to from each status
+var accountTransitions = map[AccountStatus][]AccountStatus{
+	AcctStatusPending:   {AcctStatusConfirmed, AcctStatusClosed},
+	AcctStatusConfirmed: {AcctStatusClosed},
+	AcctStatusClosed:    {},
+}
+
+// IllegalTransitionError is returned when an account cannot move from its status to the requested one
+type IllegalTransitionError struct {
+	AccountID string
+	From      AccountStatus
+	To        AccountStatus
+}
+
+func (e *IllegalTransitionError) Error() string {
+	return fmt.Sprintf("account %s cannot transition from %q to %q", e.AccountID, e.From, e.To)
+}
+
+// AllowedNext returns the statuses an account in this status can move to
+func (s AccountStatus) AllowedNext() []AccountStatus {
+	return append([]AccountStatus(nil), accountTransitions[s]...)
+}
+
+// CanTransition reports whether an account in this status can move to status to
+func (s AccountStatus) CanTransition(to AccountStatus) bool {
+	return containsStatus(accountTransitions[s], to)
+}
+
+// Transition moves an account by its id to the status to, after checking the transition is legal
+// from its current status. It returns the updated account.
+func (s *AccountsService) Transition(ctx context.Context, id string, to AccountStatus, opts ...CallOption) (*Account, *RestClientResponse, error) {
+	account, resp, err := s.Get(ctx, id, opts...)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	from := accountStatus(account)
+	if !from.CanTransition(to) {
+		return nil, resp, &IllegalTransitionError{AccountID: id, From: from, To: to}
+	}
+
+	return s.update(ctx, id, account.Version, &AccountAttributesPatch{Status: Some(to)}, opts...)
+}
+
+// Confirm moves a pending account to confirmed
+func (s *AccountsService) Confirm(ctx context.Context, id string, opts ...CallOption) (*Account, *RestClientResponse, error) {
+	return s.Transition(ctx, id, AcctStatusConfirmed, opts...)
+}
+
+// Close moves a pending or confirmed account to closed
+func (s *AccountsService) Close(ctx context.Context, id string, opts ...CallOption) (*Account, *RestClientResponse, error) {
+	return s.Transition(ctx, id, AcctStatusClosed, opts...)
+}
@@ -0,0 +1,65 @@
+package form3
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestAccountStatus_CanTransition(t *testing.T) {
+	assert.True(t, AcctStatusPending.CanTransition(AcctStatusConfirmed), "pending to confirmed should be allowed")
+	assert.True(t, AcctStatusPending.CanTransition(AcctStatusClosed), "pending to closed should be allowed")
+	assert.True(t, AcctStatusConfirmed.CanTransition(AcctStatusClosed), "confirmed to closed should be allowed")
+	assert.False(t, AcctStatusConfirmed.CanTransition(AcctStatusPending), "confirmed to pending should not be allowed")
+	assert.False(t, AcctStatusClosed.CanTransition(AcctStatusConfirmed), "closed to confirmed should not be allowed")
+	assert.False(t, AccountStatus("unknown").CanTransition(AcctStatusClosed), "unknown status should not transition")
+}
+
+func TestAccountStatus_AllowedNext(t *testing.T) {
+	assert.Equal(t, []AccountStatus{AcctStatusConfirmed, AcctStatusClosed}, AcctStatusPending.AllowedNext(), "pending next statuses incorrect")
+	assert.Empty(t, AcctStatusClosed.AllowedNext(), "closed should be final")
+
+	next := AcctStatusPending.AllowedNext()
+	next[0] = AcctStatusClosed
+	assert.Equal(t, AcctStatusConfirmed, AcctStatusPending.AllowedNext()[0], "AllowedNext should return a copy")
+}
+
+func TestAccountsService_Confirm(t *testing.T) {
+	mockedHttpClient := mockedHttpClientHandler(func(req *http.Request) (*http.Response, error) {
+		if req.Method == "GET" {
+			return mockedResponse(http.StatusOK, `{"data":{"id":"a1b2c3","type":"accounts","version":1,"attributes":{"status":"pending"}}}`, nil), nil
+		}
+
+		testRequest(t, req, testRequestExpected{
+			method: "PATCH",
+			path:   "organisation/accounts/a1b2c3",
+			body:   `{"data":{"id":"a1b2c3","type":"accounts","version":1,"attributes":{"status":"confirmed"}}}`,
+		})
+		return mockedResponse(http.StatusOK, `{"data":{"id":"a1b2c3","type":"accounts","version":2,"attributes":{"status":"confirmed"}}}`, nil), nil
+	})
+	client, _ := NewRestClient(mockedHttpClient, NewRestClientParams{BaseUrl: baseFakeUrl})
+
+	account, resp, err := NewAccountsService(client).Confirm(context.Background(), "a1b2c3")
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "Response code incorrect")
+	assert.Equal(t, AcctStatusConfirmed, account.Attributes.Status, "account.Attributes.Status incorrect")
+}
+
+func TestAccountsService_Close_illegalTransition(t *testing.T) {
+	mockedHttpClient := mockedHttpClientHandler(func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, "GET", req.Method, "Only the account should be retrieved")
+		return mockedResponse(http.StatusOK, `{"data":{"id":"a1b2c3","type":"accounts","version":3,"attributes":{"status":"closed"}}}`, nil), nil
+	})
+	client, _ := NewRestClient(mockedHttpClient, NewRestClientParams{BaseUrl: baseFakeUrl})
+
+	account, _, err := NewAccountsService(client).Close(context.Background(), "a1b2c3")
+
+	assert.Nil(t, account, "Account should be nil")
+	var transitionErr *IllegalTransitionError
+	assert.True(t, errors.As(err, &transitionErr), "Error should be an IllegalTransitionError")
+	assert.Equal(t, AcctStatusClosed, transitionErr.From, "From status incorrect")
+	assert.Equal(t, `account a1b2c3 cannot transition from "closed" to "closed"`, err.Error(), "Error message expected")
+}
@@ -4,19 +4,20 @@ import (
 	"context"
 	"fmt"
 	"form3-interview-accountapi/form3"
+	"form3-interview-accountapi/form3/conformance"
+	"form3-interview-accountapi/form3/form3test"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"net/http"
-	"os"
 	"testing"
 )
 
 func TestAccountsService_Create(t *testing.T) {
-	service, err := getNewAccountsService()
+	service, err := getNewAccountsService(t)
 	if err != nil {
 		t.Fatalf("Error creating AccountsService: %v", err)
 	}
-	account := generateTestAccount()
+	account := conformance.NewAccount()
 
 	newAccount, resp, err := service.Create(context.Background(), account)
 
@@ -30,7 +31,7 @@ func TestAccountsService_Create(t *testing.T) {
 	assert.NotNil(t, newAccount.CreatedOn)
 	assert.NotNil(t, newAccount.ModifiedOn)
 	assert.Equal(t, account.ID, newAccount.ID)
-	assert.Equal(t, account.OrganisationID, newAccount.OrganisationID)
+	assert.Equal(t, service.OrganisationID(), newAccount.OrganisationID)
 	assert.Equal(t, account.Attributes.AccountClassification, newAccount.Attributes.AccountClassification)
 	assert.Equal(t, account.Attributes.AlternativeNames, newAccount.Attributes.AlternativeNames)
 	assert.Equal(t, account.Attributes.BankID, newAccount.Attributes.BankID)
@@ -52,12 +53,12 @@ func TestAccountsService_Create(t *testing.T) {
 }
 
 func TestAccountsService_Create_ErrorResponse(t *testing.T) {
-	service, err := getNewAccountsService()
+	service, err := getNewAccountsService(t)
 	if err != nil {
 		t.Fatalf("Error creating AccountsService: %v", err)
 	}
 
-	account := generateTestAccount()
+	account := conformance.NewAccount()
 	account.ID = "invalid-UUID"
 
 	newAccount, resp, err := service.Create(context.Background(), account)
@@ -72,12 +73,12 @@ func TestAccountsService_Create_ErrorResponse(t *testing.T) {
 }
 
 func TestAccountsService_Get(t *testing.T) {
-	service, err := getNewAccountsService()
+	service, err := getNewAccountsService(t)
 	if err != nil {
 		t.Fatalf("Error creating AccountsService: %v", err)
 	}
 
-	account := generateTestAccount()
+	account := conformance.NewAccount()
 	ctx := context.Background()
 	newAccount, _, _ := service.Create(ctx, account)
 
@@ -94,7 +95,7 @@ func TestAccountsService_Get(t *testing.T) {
 }
 
 func TestAccountsService_Get_ErrorResponse(t *testing.T) {
-	service, err := getNewAccountsService()
+	service, err := getNewAccountsService(t)
 	if err != nil {
 		t.Fatalf("Error creating AccountsService: %v", err)
 	}
@@ -111,7 +112,7 @@ func TestAccountsService_Get_ErrorResponse(t *testing.T) {
 }
 
 func TestAccountsService_Get_ErrorNotFound(t *testing.T) {
-	service, err := getNewAccountsService()
+	service, err := getNewAccountsService(t)
 	if err != nil {
 		t.Fatalf("Error creating AccountsService: %v", err)
 	}
@@ -129,12 +130,12 @@ func TestAccountsService_Get_ErrorNotFound(t *testing.T) {
 }
 
 func TestAccountsService_Delete(t *testing.T) {
-	service, err := getNewAccountsService()
+	service, err := getNewAccountsService(t)
 	if err != nil {
 		t.Fatalf("Error creating AccountsService: %v", err)
 	}
 
-	account := generateTestAccount()
+	account := conformance.NewAccount()
 	ctx := context.Background()
 	_, _, err = service.Create(ctx, account)
 	assert.Nil(t, err)
@@ -148,12 +149,19 @@ func TestAccountsService_Delete(t *testing.T) {
 }
 
 func TestAccountsService_Delete_ErrorNotFound(t *testing.T) {
-	service, err := getNewAccountsService()
+	service, err := getNewAccountsService(t)
 	if err != nil {
 		t.Fatalf("Error creating AccountsService: %v", err)
 	}
 
-	resp, err := service.Delete(context.Background(), uuid.New().String(), 0)
+	account := conformance.NewAccount()
+	ctx := context.Background()
+	_, _, err = service.Create(ctx, account)
+	assert.Nil(t, err)
+	_, err = service.DeleteLatest(ctx, account.ID)
+	assert.Nil(t, err)
+
+	resp, err := service.Delete(ctx, account.ID, 0)
 
 	assert.Nil(t, err)
 
@@ -162,12 +170,12 @@ func TestAccountsService_Delete_ErrorNotFound(t *testing.T) {
 }
 
 func TestAccountsService_Delete_ErrorInvalidVersion(t *testing.T) {
-	service, err := getNewAccountsService()
+	service, err := getNewAccountsService(t)
 	if err != nil {
 		t.Fatalf("Error creating AccountsService: %v", err)
 	}
 
-	account := generateTestAccount()
+	account := conformance.NewAccount()
 	ctx := context.Background()
 	_, _, err = service.Create(ctx, account)
 	assert.Nil(t, err)
@@ -181,30 +189,36 @@ func TestAccountsService_Delete_ErrorInvalidVersion(t *testing.T) {
 	assert.Equal(t, http.StatusConflict, resp.StatusCode)
 }
 
-func getNewAccountsService() (*form3.AccountsService, error) {
-	baseUrl := os.Getenv("API_URL")
-	client, err := form3.NewRestClient(nil, form3.NewRestClientParams{BaseUrl: baseUrl})
+func TestAccountsService_DeleteLatest(t *testing.T) {
+	service, err := getNewAccountsService(t)
 	if err != nil {
-		return nil, err
+		t.Fatalf("Error creating AccountsService: %v", err)
 	}
 
-	return form3.NewAccountsService(client), nil
+	account := conformance.NewAccount()
+	ctx := context.Background()
+	_, _, err = service.Create(ctx, account)
+	assert.Nil(t, err)
+
+	resp, err := service.DeleteLatest(ctx, account.ID)
+
+	assert.Nil(t, err)
+
+	assert.NotNil(t, resp)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	resp, err = service.DeleteLatest(ctx, account.ID, form3.WithNotFoundAsSuccess())
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
 }
 
-func generateTestAccount() *form3.Account {
-	return &form3.Account{
-		ID:             uuid.New().String(),
-		OrganisationID: uuid.New().String(),
-		Type:           form3.AcctTypeAccounts,
-		Attributes: &form3.AccountAttributes{
-			AccountClassification: form3.AcctClassificationPersonal,
-			AlternativeNames:      []string{"foo", "bar"},
-			BankID:                "ZXE",
-			BankIDCode:            form3.BankIDCodeBelgium,
-			BaseCurrency:          form3.BaseCurrencyEur,
-			Country:               form3.CountryCodeBelgium,
-			Name:                  []string{"cristian", "pelegrin"},
-			Status:                form3.AcctStatusPending,
-		},
+// getNewAccountsService returns a service deleting the accounts created by the test when it ends
+func getNewAccountsService(t *testing.T) (*form3test.Sandbox, error) {
+	client, err := form3.NewRestClientFromEnv()
+	if err != nil {
+		return nil, err
 	}
+
+	return form3test.NewSandbox(t, form3.NewAccountsService(client)), nil
 }
@@ -0,0 +1,17 @@
+package integration
+
+import (
+	"form3-interview-accountapi/form3"
+	"form3-interview-accountapi/form3/conformance"
+	"form3-interview-accountapi/form3/conformance/conformancetest"
+	"testing"
+)
+
+func TestConformance(t *testing.T) {
+	client, err := form3.NewRestClientFromEnv()
+	if err != nil {
+		t.Fatalf("Error creating RestClient: %v", err)
+	}
+
+	conformancetest.RunT(t, conformance.Config{Client: client})
+}
@@ -0,0 +1,232 @@
+package form3
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	defaultDialTimeout           = 30 * time.Second
+	defaultTLSHandshakeTimeout   = 10 * time.Second
+	defaultResponseHeaderTimeout = 30 * time.Second
+	defaultIdleConnTimeout       = 90 * time.Second
+	defaultMaxIdleConns          = 100
+	defaultMaxIdleConnsPerHost   = 10
+	defaultCertReloadInterval    = time.Minute
+)
+
+// TransportConfig configures the http.Transport built by NewTransport.
+// Zero values fall back to defaults similar to http.DefaultTransport plus a response header timeout.
+type TransportConfig struct {
+	// ClientCertFile and ClientKeyFile are PEM files of the client certificate used for mTLS.
+	// They are read again when they change, so rotated certificates are used without restarting.
+	ClientCertFile string
+	ClientKeyFile  string
+	// ClientCertPEM and ClientKeyPEM are an in-memory alternative to the certificate files
+	ClientCertPEM []byte
+	ClientKeyPEM  []byte
+	// CertReloadInterval is how often the certificate files are checked for changes, 1 minute by default
+	CertReloadInterval time.Duration
+
+	// RootCAFiles and RootCAPEM are PEM certificates trusted to verify the server, the system pool is used if empty
+	RootCAFiles []string
+	RootCAPEM   []byte
+	// MinTLSVersion is the minimum TLS version accepted, tls.VersionTLS12 by default
+	MinTLSVersion uint16
+
+	// ProxyURL is the proxy used for all the requests, HTTP_PROXY and related env variables are used if empty
+	ProxyURL string
+
+	MaxIdleConns          int
+	MaxIdleConnsPerHost   int
+	MaxConnsPerHost       int
+	IdleConnTimeout       time.Duration
+	DialTimeout           time.Duration
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration
+
+	// DisableHTTP2 stops the transport from negotiating HTTP/2
+	DisableHTTP2 bool
+	// DisableCompression stops the transport from requesting gzip responses
+	DisableCompression bool
+}
+
+// NewTransport returns a http.Transport built from the config
+func NewTransport(config TransportConfig) (*http.Transport, error) {
+	tlsConfig, err := newTLSConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	proxy := http.ProxyFromEnvironment
+	if config.ProxyURL != "" {
+		proxyURL, err := url.Parse(config.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy url: %w", err)
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   durationOrDefault(config.DialTimeout, defaultDialTimeout),
+		KeepAlive: 30 * time.Second,
+	}
+
+	transport := &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           dialer.DialContext,
+		TLSClientConfig:       tlsConfig,
+		ForceAttemptHTTP2:     !config.DisableHTTP2,
+		DisableCompression:    config.DisableCompression,
+		MaxIdleConns:          intOrDefault(config.MaxIdleConns, defaultMaxIdleConns),
+		MaxIdleConnsPerHost:   intOrDefault(config.MaxIdleConnsPerHost, defaultMaxIdleConnsPerHost),
+		MaxConnsPerHost:       config.MaxConnsPerHost,
+		IdleConnTimeout:       durationOrDefault(config.IdleConnTimeout, defaultIdleConnTimeout),
+		TLSHandshakeTimeout:   durationOrDefault(config.TLSHandshakeTimeout, defaultTLSHandshakeTimeout),
+		ResponseHeaderTimeout: durationOrDefault(config.ResponseHeaderTimeout, defaultResponseHeaderTimeout),
+		ExpectContinueTimeout: time.Second,
+	}
+	if config.DisableHTTP2 {
+		// a non-nil empty map is the documented way to disable HTTP/2
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+
+	return transport, nil
+}
+
+func newTLSConfig(config TransportConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: config.MinTLSVersion,
+	}
+	if tlsConfig.MinVersion == 0 {
+		tlsConfig.MinVersion = tls.VersionTLS12
+	}
+
+	if len(config.RootCAFiles) > 0 || len(config.RootCAPEM) > 0 {
+		pool := x509.NewCertPool()
+		for _, file := range config.RootCAFiles {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("reading root CA: %w", err)
+			}
+			if !pool.AppendCertsFromPEM(data) {
+				return nil, fmt.Errorf("no certificates found in root CA file %s", file)
+			}
+		}
+		if len(config.RootCAPEM) > 0 && !pool.AppendCertsFromPEM(config.RootCAPEM) {
+			return nil, errors.New("no certificates found in root CA PEM")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	switch {
+	case config.ClientCertFile != "" || config.ClientKeyFile != "":
+		reloader, err := newCertReloader(config.ClientCertFile, config.ClientKeyFile,
+			durationOrDefault(config.CertReloadInterval, defaultCertReloadInterval))
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.GetClientCertificate = reloader.getClientCertificate
+	case len(config.ClientCertPEM) > 0 || len(config.ClientKeyPEM) > 0:
+		cert, err := tls.X509KeyPair(config.ClientCertPEM, config.ClientKeyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// certReloader serves the client certificate of a pair of files, loading them again when they change
+type certReloader struct {
+	certFile  string
+	keyFile   string
+	interval  time.Duration
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	modTime   time.Time
+	lastCheck time.Time
+}
+
+func newCertReloader(certFile string, keyFile string, interval time.Duration) (*certReloader, error) {
+	reloader := &certReloader{certFile: certFile, keyFile: keyFile, interval: interval}
+	if err := reloader.load(); err != nil {
+		return nil, err
+	}
+
+	return reloader, nil
+}
+
+func (r *certReloader) getClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Since(r.lastCheck) >= r.interval {
+		// a failed reload, e.g. while the files are being rotated, keeps the current certificate
+		_ = r.load()
+	}
+
+	return r.cert, nil
+}
+
+// load reads the files if they changed since the last load
+func (r *certReloader) load() error {
+	r.lastCheck = time.Now()
+
+	modTime, err := latestModTime(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("loading client certificate: %w", err)
+	}
+	if r.cert != nil && modTime.Equal(r.modTime) {
+		return nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("loading client certificate: %w", err)
+	}
+	r.cert = &cert
+	r.modTime = modTime
+
+	return nil
+}
+
+func latestModTime(files ...string) (time.Time, error) {
+	var latest time.Time
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+
+	return latest, nil
+}
+
+func durationOrDefault(value time.Duration, defaultValue time.Duration) time.Duration {
+	if value <= 0 {
+		return defaultValue
+	}
+
+	return value
+}
+
+func intOrDefault(value int, defaultValue int) int {
+	if value <= 0 {
+		return defaultValue
+	}
+
+	return value
+}
@@ -0,0 +1,170 @@
+package form3
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testCertificateAuthority struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCertificateAuthority(t *testing.T) *testCertificateAuthority {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Error creating CA certificate: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+
+	return &testCertificateAuthority{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// clientCertificate returns the PEM certificate and key of a client signed by the CA
+func (ca *testCertificateAuthority) clientCertificate(t *testing.T, commonName string) ([]byte, []byte) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("Error creating client certificate: %v", err)
+	}
+	keyDer, _ := x509.MarshalECPrivateKey(key)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+}
+
+// newMTLSServer returns a server requiring client certificates signed by ca, answering the client common name
+func newMTLSServer(t *testing.T, ca *testCertificateAuthority) (*httptest.Server, []byte) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+	server.TLS = &tls.Config{ClientCAs: clientCAs, ClientAuth: tls.RequireAndVerifyClientCert}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	serverCA := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	return server, serverCA
+}
+
+func getCommonName(t *testing.T, client *http.Client, url string) string {
+	t.Helper()
+
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatalf("Error calling the server: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	return string(body)
+}
+
+func TestNewTransport_inMemoryClientCertificate(t *testing.T) {
+	ca := newTestCertificateAuthority(t)
+	server, serverCA := newMTLSServer(t, ca)
+	certPEM, keyPEM := ca.clientCertificate(t, "client-1")
+
+	transport, err := NewTransport(TransportConfig{
+		ClientCertPEM: certPEM,
+		ClientKeyPEM:  keyPEM,
+		RootCAPEM:     serverCA,
+		MinTLSVersion: tls.VersionTLS13,
+	})
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, uint16(tls.VersionTLS13), transport.TLSClientConfig.MinVersion, "MinVersion incorrect")
+	assert.Equal(t, "client-1", getCommonName(t, &http.Client{Transport: transport}, server.URL), "Client certificate incorrect")
+}
+
+func TestNewTransport_reloadsRotatedCertificateFiles(t *testing.T) {
+	ca := newTestCertificateAuthority(t)
+	server, serverCA := newMTLSServer(t, ca)
+	dir := t.TempDir()
+	certFile, keyFile, caFile := filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key"), filepath.Join(dir, "ca.crt")
+	writeCertificate := func(commonName string, modTime time.Time) {
+		certPEM, keyPEM := ca.clientCertificate(t, commonName)
+		_ = os.WriteFile(certFile, certPEM, 0o600)
+		_ = os.WriteFile(keyFile, keyPEM, 0o600)
+		_ = os.Chtimes(certFile, modTime, modTime)
+		_ = os.Chtimes(keyFile, modTime, modTime)
+	}
+	writeCertificate("client-1", time.Now().Add(-time.Minute))
+	_ = os.WriteFile(caFile, serverCA, 0o600)
+
+	transport, err := NewTransport(TransportConfig{
+		ClientCertFile:     certFile,
+		ClientKeyFile:      keyFile,
+		RootCAFiles:        []string{caFile},
+		CertReloadInterval: time.Nanosecond,
+	})
+	assert.Nil(t, err, "Error should be nil")
+	client := &http.Client{Transport: transport}
+
+	assert.Equal(t, "client-1", getCommonName(t, client, server.URL), "Client certificate incorrect")
+
+	writeCertificate("client-2", time.Now())
+	transport.CloseIdleConnections()
+
+	assert.Equal(t, "client-2", getCommonName(t, client, server.URL), "Rotated client certificate should be used")
+}
+
+func TestNewTransport_defaults(t *testing.T) {
+	transport, err := NewTransport(TransportConfig{DisableHTTP2: true, ProxyURL: "http://proxy.local:3128"})
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, uint16(tls.VersionTLS12), transport.TLSClientConfig.MinVersion, "MinVersion incorrect")
+	assert.Equal(t, defaultResponseHeaderTimeout, transport.ResponseHeaderTimeout, "ResponseHeaderTimeout incorrect")
+	assert.Equal(t, defaultMaxIdleConnsPerHost, transport.MaxIdleConnsPerHost, "MaxIdleConnsPerHost incorrect")
+	assert.False(t, transport.ForceAttemptHTTP2, "HTTP/2 should be disabled")
+	assert.NotNil(t, transport.TLSNextProto, "TLSNextProto should disable HTTP/2")
+
+	req, _ := http.NewRequest("GET", baseFakeUrl, nil)
+	proxyURL, _ := transport.Proxy(req)
+	assert.Equal(t, "http://proxy.local:3128", proxyURL.String(), "Proxy incorrect")
+}
+
+func TestNewTransport_invalidConfig(t *testing.T) {
+	_, err := NewTransport(TransportConfig{ClientCertFile: "missing.crt", ClientKeyFile: "missing.key"})
+	assert.NotNil(t, err, "Missing certificate files should fail")
+
+	_, err = NewTransport(TransportConfig{RootCAPEM: []byte("not a certificate")})
+	assert.NotNil(t, err, "Invalid root CA should fail")
+
+	_, err = NewRestClient(nil, NewRestClientParams{BaseUrl: baseFakeUrl, Transport: &TransportConfig{ClientCertPEM: []byte("foo")}})
+	assert.NotNil(t, err, "NewRestClient should fail with an invalid transport")
+}
@@ -1,6 +1,8 @@
 package form3
 
 import (
+	"context"
+	"encoding/json"
 	"net/http"
 	"net/url"
 	"time"
@@ -11,8 +13,17 @@ type HttpClient interface {
 }
 
 type RestClient struct {
-	httpClient HttpClient
-	baseURL    *url.URL
+	httpClient      HttpClient
+	baseURL         *url.URL
+	maxResponseSize int64
+	decodeMode      DecodeMode
+	retryPolicy     *RetryPolicy
+	timeout         time.Duration
+	rateLimiter     *rateLimiter
+	authToken       string
+	logger          Logger
+	coalescer       *coalescer
+	hedger          *hedger
 }
 
 type RestClientRequest struct {
@@ -21,6 +32,26 @@ type RestClientRequest struct {
 
 type RestClientResponse struct {
 	*http.Response
+	// Links and Meta are the JSON:API members of the response envelope, nil when not sent
+	Links *Links
+	Meta  *Meta
+}
+
+// Links holds the links of a response envelope, relative to the API host
+type Links struct {
+	Self  string `json:"self,omitempty"`
+	First string `json:"first,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+	Next  string `json:"next,omitempty"`
+	Last  string `json:"last,omitempty"`
+}
+
+// Meta holds the metadata of a response envelope. Members not modelled are kept in Extra
+type Meta struct {
+	// Count is the number of resources matching the request, nil when not sent
+	Count      *int                       `json:"count,omitempty"`
+	TotalPages int                        `json:"total_pages,omitempty"`
+	Extra      map[string]json.RawMessage `json:"-"`
 }
 
 type service struct {
@@ -31,6 +62,33 @@ type AccountsService struct {
 	service
 }
 
+// AccountsAPI is the set of account operations, satisfied by AccountsService.
+// Depend on it instead of AccountsService to substitute the API in tests, see the form3mock package
+type AccountsAPI interface {
+	Create(ctx context.Context, data *Account, opts ...CallOption) (*Account, *RestClientResponse, error)
+	Get(ctx context.Context, id string, opts ...CallOption) (*Account, *RestClientResponse, error)
+	List(ctx context.Context, listOpts *ListOptions, opts ...CallOption) ([]Account, *RestClientResponse, error)
+	ListEach(ctx context.Context, listOpts *ListOptions, fn func(account *Account) error, opts ...CallOption) error
+	Update(ctx context.Context, id string, version int, attributes *AccountAttributesPatch, opts ...CallOption) (*Account, *RestClientResponse, error)
+	Delete(ctx context.Context, id string, version int, opts ...CallOption) (*RestClientResponse, error)
+	DeleteLatest(ctx context.Context, id string, opts ...DeleteLatestOption) (*RestClientResponse, error)
+	Transition(ctx context.Context, id string, to AccountStatus, opts ...CallOption) (*Account, *RestClientResponse, error)
+	Confirm(ctx context.Context, id string, opts ...CallOption) (*Account, *RestClientResponse, error)
+	Close(ctx context.Context, id string, opts ...CallOption) (*Account, *RestClientResponse, error)
+	WaitForStatus(ctx context.Context, id string, opts *WaitOptions, targetStatuses ...AccountStatus) (*Account, error)
+	Watch(ctx context.Context, filter *WatchFilter) (<-chan AccountEvent, error)
+}
+
+var _ AccountsAPI = (*AccountsService)(nil)
+
+type MandatesService struct {
+	service
+}
+
+type AuditService struct {
+	service
+}
+
 type AccountType string
 
 const (
@@ -45,6 +103,19 @@ type Account struct {
 	Version        int                `json:"version"`
 	CreatedOn      *time.Time         `json:"created_on,omitempty"`
 	ModifiedOn     *time.Time         `json:"modified_on,omitempty"`
+	// Extra holds the members not modelled by Account when decoded with DecodeModePreserveUnknown
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// MarshalJSON encodes the account including its Extra members
+func (a Account) MarshalJSON() ([]byte, error) {
+	type account Account
+	data, err := json.Marshal(account(a))
+	if err != nil {
+		return nil, err
+	}
+
+	return marshalWithExtra(data, a.Extra)
 }
 
 type AccountClassification string
@@ -58,9 +129,11 @@ type BankIDCode string
 
 // in real production code We should add here a const for each possible bank ID listed in the doc
 const (
-	BankIDCodeBelgium BankIDCode = "BE"
-	BankIDCodeEstonia BankIDCode = "EE"
-	BankIDCodeFrance  BankIDCode = "FR"
+	BankIDCodeBelgium       BankIDCode = "BE"
+	BankIDCodeEstonia       BankIDCode = "EE"
+	BankIDCodeFrance        BankIDCode = "FR"
+	BankIDCodeGermany       BankIDCode = "DEBLZ"
+	BankIDCodeUnitedKingdom BankIDCode = "GBDSC"
 )
 
 type BaseCurrency string
@@ -68,6 +141,7 @@ type BaseCurrency string
 const (
 	BaseCurrencyEur BaseCurrency = "EUR"
 	BaseCurrencyUsd BaseCurrency = "USD"
+	BaseCurrencyGbp BaseCurrency = "GBP"
 )
 
 type AccountStatus string
@@ -82,25 +156,248 @@ type CountryCode string
 
 // in real production code We should add here a const for each possible country listed in the doc
 const (
-	CountryCodeBelgium CountryCode = "BE"
-	CountryCodeEstonia CountryCode = "EE"
-	CountryCodeFrance  CountryCode = "FR"
+	CountryCodeBelgium       CountryCode = "BE"
+	CountryCodeEstonia       CountryCode = "EE"
+	CountryCodeFrance        CountryCode = "FR"
+	CountryCodeGermany       CountryCode = "DE"
+	CountryCodeUnitedKingdom CountryCode = "GB"
+)
+
+type NameMatchingStatus string
+
+const (
+	NameMatchingStatusSupported    NameMatchingStatus = "supported"
+	NameMatchingStatusSwitched     NameMatchingStatus = "switched"
+	NameMatchingStatusOptedOut     NameMatchingStatus = "opted_out"
+	NameMatchingStatusNotSupported NameMatchingStatus = "not_supported"
+)
+
+type ValidationType string
+
+const (
+	ValidationTypeCard ValidationType = "card"
 )
 
 type AccountAttributes struct {
-	AccountClassification   AccountClassification `json:"account_classification,omitempty"`
-	AccountMatchingOptOut   bool                  `json:"account_matching_opt_out,omitempty"`
-	AccountNumber           string                `json:"account_number,omitempty"`
-	AlternativeNames        []string              `json:"alternative_names,omitempty"`
-	BankID                  string                `json:"bank_id,omitempty"`
-	BankIDCode              BankIDCode            `json:"bank_id_code,omitempty"`
-	BaseCurrency            BaseCurrency          `json:"base_currency,omitempty"`
-	Bic                     string                `json:"bic,omitempty"`
-	Country                 CountryCode           `json:"country,omitempty"`
-	Iban                    string                `json:"iban,omitempty"`
-	JointAccount            bool                  `json:"joint_account,omitempty"`
-	Name                    []string              `json:"name,omitempty"`
-	SecondaryIdentification string                `json:"secondary_identification,omitempty"`
-	Status                  AccountStatus         `json:"status,omitempty"`
-	Switched                bool                  `json:"switched,omitempty"`
+	AcceptanceQualifier        string                      `json:"acceptance_qualifier,omitempty"`
+	AccountClassification      AccountClassification       `json:"account_classification,omitempty"`
+	AccountMatchingOptOut      bool                        `json:"account_matching_opt_out,omitempty"`
+	AccountNumber              string                      `json:"account_number,omitempty"`
+	AlternativeNames           []string                    `json:"alternative_names,omitempty"`
+	BankID                     string                      `json:"bank_id,omitempty"`
+	BankIDCode                 BankIDCode                  `json:"bank_id_code,omitempty"`
+	BaseCurrency               BaseCurrency                `json:"base_currency,omitempty"`
+	Bic                        string                      `json:"bic,omitempty"`
+	Country                    CountryCode                 `json:"country,omitempty"`
+	Iban                       string                      `json:"iban,omitempty"`
+	JointAccount               bool                        `json:"joint_account,omitempty"`
+	Name                       []string                    `json:"name,omitempty"`
+	NameMatchingStatus         NameMatchingStatus          `json:"name_matching_status,omitempty"`
+	OrganisationIdentification *OrganisationIdentification `json:"organisation_identification,omitempty"`
+	PrivateIdentification      *PrivateIdentification      `json:"private_identification,omitempty"`
+	ProcessingService          string                      `json:"processing_service,omitempty"`
+	ReferenceMask              string                      `json:"reference_mask,omitempty"`
+	SecondaryIdentification    string                      `json:"secondary_identification,omitempty"`
+	Status                     AccountStatus               `json:"status,omitempty"`
+	Switched                   bool                        `json:"switched,omitempty"`
+	UserDefinedInformation     string                      `json:"user_defined_information,omitempty"`
+	ValidationType             ValidationType              `json:"validation_type,omitempty"`
+	// Extra holds the members not modelled by AccountAttributes when decoded with DecodeModePreserveUnknown
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// MarshalJSON encodes the attributes including its Extra members
+func (a AccountAttributes) MarshalJSON() ([]byte, error) {
+	type accountAttributes AccountAttributes
+	data, err := json.Marshal(accountAttributes(a))
+	if err != nil {
+		return nil, err
+	}
+
+	return marshalWithExtra(data, a.Extra)
+}
+
+// PrivateIdentification identifies the holder of a Personal account
+type PrivateIdentification struct {
+	BirthDate      string      `json:"birth_date,omitempty"`
+	BirthCountry   CountryCode `json:"birth_country,omitempty"`
+	Identification string      `json:"identification,omitempty"`
+	Address        []string    `json:"address,omitempty"`
+	City           string      `json:"city,omitempty"`
+	Country        CountryCode `json:"country,omitempty"`
+}
+
+// OrganisationIdentification identifies the holder of a Business account
+type OrganisationIdentification struct {
+	Identification string              `json:"identification,omitempty"`
+	Actors         []OrganisationActor `json:"actors,omitempty"`
+	Address        []string            `json:"address,omitempty"`
+	City           string              `json:"city,omitempty"`
+	Country        CountryCode         `json:"country,omitempty"`
+}
+
+type OrganisationActor struct {
+	Name      []string    `json:"name,omitempty"`
+	BirthDate string      `json:"birth_date,omitempty"`
+	Residency CountryCode `json:"residency,omitempty"`
+}
+
+// AccountPatch is the payload of an account update, only the present attributes are changed
+type AccountPatch struct {
+	ID         string                  `json:"id"`
+	Type       AccountType             `json:"type"`
+	Version    int                     `json:"version"`
+	Attributes *AccountAttributesPatch `json:"attributes,omitempty"`
+}
+
+// AccountAttributesPatch mirrors AccountAttributes with Optional fields, so it tells apart attributes
+// left unchanged (absent), cleared (null) and set, including false and empty values
+type AccountAttributesPatch struct {
+	AcceptanceQualifier        Optional[string]                      `json:"acceptance_qualifier"`
+	AccountClassification      Optional[AccountClassification]       `json:"account_classification"`
+	AccountMatchingOptOut      Optional[bool]                        `json:"account_matching_opt_out"`
+	AccountNumber              Optional[string]                      `json:"account_number"`
+	AlternativeNames           Optional[[]string]                    `json:"alternative_names"`
+	BankID                     Optional[string]                      `json:"bank_id"`
+	BankIDCode                 Optional[BankIDCode]                  `json:"bank_id_code"`
+	BaseCurrency               Optional[BaseCurrency]                `json:"base_currency"`
+	Bic                        Optional[string]                      `json:"bic"`
+	Country                    Optional[CountryCode]                 `json:"country"`
+	Iban                       Optional[string]                      `json:"iban"`
+	JointAccount               Optional[bool]                        `json:"joint_account"`
+	Name                       Optional[[]string]                    `json:"name"`
+	NameMatchingStatus         Optional[NameMatchingStatus]          `json:"name_matching_status"`
+	OrganisationIdentification Optional[*OrganisationIdentification] `json:"organisation_identification"`
+	PrivateIdentification      Optional[*PrivateIdentification]      `json:"private_identification"`
+	ProcessingService          Optional[string]                      `json:"processing_service"`
+	ReferenceMask              Optional[string]                      `json:"reference_mask"`
+	SecondaryIdentification    Optional[string]                      `json:"secondary_identification"`
+	Status                     Optional[AccountStatus]               `json:"status"`
+	Switched                   Optional[bool]                        `json:"switched"`
+	UserDefinedInformation     Optional[string]                      `json:"user_defined_information"`
+	ValidationType             Optional[ValidationType]              `json:"validation_type"`
+}
+
+// MarshalJSON leaves out the absent attributes
+func (a AccountAttributesPatch) MarshalJSON() ([]byte, error) {
+	return MarshalPatch(a)
+}
+
+type MandateType string
+
+const (
+	MandateTypeMandates MandateType = "mandates"
+)
+
+type MandateScheme string
+
+const (
+	MandateSchemeBacs            MandateScheme = "bacs"
+	MandateSchemeSepaDirectDebit MandateScheme = "sepadirectdebit"
+)
+
+type MandateStatus string
+
+const (
+	MandateStatusPending   MandateStatus = "pending"
+	MandateStatusActive    MandateStatus = "active"
+	MandateStatusCancelled MandateStatus = "cancelled"
+)
+
+type Mandate struct {
+	ID             string                `json:"id"`
+	OrganisationID string                `json:"organisation_id"`
+	Type           MandateType           `json:"type"`
+	Attributes     *MandateAttributes    `json:"attributes,omitempty"`
+	Relationships  *MandateRelationships `json:"relationships,omitempty"`
+	Version        int                   `json:"version"`
+	CreatedOn      *time.Time            `json:"created_on,omitempty"`
+	ModifiedOn     *time.Time            `json:"modified_on,omitempty"`
+}
+
+type MandateAttributes struct {
+	Scheme        MandateScheme `json:"scheme,omitempty"`
+	Reference     string        `json:"reference,omitempty"`
+	Status        MandateStatus `json:"status,omitempty"`
+	DebtorParty   *MandateParty `json:"debtor_party,omitempty"`
+	CreditorParty *MandateParty `json:"creditor_party,omitempty"`
+	SignatureDate string        `json:"signature_date,omitempty"`
+}
+
+// MandatePatch is the payload of a mandate update, only the status can be changed
+type MandatePatch struct {
+	ID         string                  `json:"id"`
+	Type       MandateType             `json:"type"`
+	Version    int                     `json:"version"`
+	Attributes *MandateAttributesPatch `json:"attributes"`
+}
+
+type MandateAttributesPatch struct {
+	Status MandateStatus `json:"status"`
+}
+
+type MandateParty struct {
+	AccountName   string      `json:"account_name,omitempty"`
+	AccountNumber string      `json:"account_number,omitempty"`
+	BankID        string      `json:"bank_id,omitempty"`
+	BankIDCode    BankIDCode  `json:"bank_id_code,omitempty"`
+	Country       CountryCode `json:"country,omitempty"`
+	Name          []string    `json:"name,omitempty"`
+}
+
+type MandateRelationships struct {
+	Account *Relationship `json:"account,omitempty"`
+}
+
+// Relationship links a resource to others following the JSON:API relationships format
+type Relationship struct {
+	Data []ResourceIdentifier `json:"data"`
+}
+
+type ResourceIdentifier struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+// ListOptions holds the paging and filter parameters accepted by the list endpoints
+type ListOptions struct {
+	PageNumber int
+	PageSize   int
+	Filter     map[string]string
+	// Sort is the sort query parameter, e.g. a field name, left to the API default when empty
+	Sort string
+}
+
+type AuditEntryType string
+
+const (
+	AuditEntryTypeAuditEntries AuditEntryType = "audit_entries"
+)
+
+type AuditAction string
+
+const (
+	AuditActionCreate AuditAction = "create"
+	AuditActionUpdate AuditAction = "update"
+	AuditActionDelete AuditAction = "delete"
+)
+
+type AuditEntry struct {
+	ID             string                `json:"id"`
+	OrganisationID string                `json:"organisation_id"`
+	Type           AuditEntryType        `json:"type"`
+	Attributes     *AuditEntryAttributes `json:"attributes,omitempty"`
+}
+
+// AuditEntryAttributes keeps the before and after snapshots undecoded because their shape depends on RecordType
+type AuditEntryAttributes struct {
+	Action              AuditAction     `json:"action,omitempty"`
+	ActionTime          *time.Time      `json:"action_time,omitempty"`
+	ActorName           string          `json:"actor_name,omitempty"`
+	ActorOrganisationID string          `json:"actor_organisation_id,omitempty"`
+	Description         string          `json:"description,omitempty"`
+	RecordType          string          `json:"record_type,omitempty"`
+	RecordID            string          `json:"record_id,omitempty"`
+	BeforeData          json.RawMessage `json:"before_data,omitempty"`
+	AfterData           json.RawMessage `json:"after_data,omitempty"`
 }
@@ -0,0 +1,56 @@
+package form3
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAccount_roundTrip_personal(t *testing.T) {
+	data := `{"id":"a1b2c3","organisation_id":"b","type":"accounts","attributes":{` +
+		`"account_classification":"Personal","bank_id":"400300","bank_id_code":"GBDSC","country":"GB","name":["cristian","pelegrin"],` +
+		`"name_matching_status":"supported",` +
+		`"private_identification":{"birth_date":"1990-01-31","birth_country":"ES","identification":"13YH458762",` +
+		`"address":["10 Avenue des Champs"],"city":"London","country":"GB"},` +
+		`"processing_service":"ABC Bank","reference_mask":"############","status":"confirmed",` +
+		`"user_defined_information":"Some important info","validation_type":"card"},"version":0}`
+
+	account := new(Account)
+	err := json.Unmarshal([]byte(data), account)
+
+	assert.Nil(t, err, "Error should be nil")
+	identification := account.Attributes.PrivateIdentification
+	assert.Equal(t, "1990-01-31", identification.BirthDate, "PrivateIdentification.BirthDate incorrect")
+	assert.Equal(t, CountryCode("ES"), identification.BirthCountry, "PrivateIdentification.BirthCountry incorrect")
+	assert.Equal(t, "13YH458762", identification.Identification, "PrivateIdentification.Identification incorrect")
+	assert.Equal(t, []string{"10 Avenue des Champs"}, identification.Address, "PrivateIdentification.Address incorrect")
+	assert.Equal(t, NameMatchingStatusSupported, account.Attributes.NameMatchingStatus, "NameMatchingStatus incorrect")
+	assert.Equal(t, ValidationTypeCard, account.Attributes.ValidationType, "ValidationType incorrect")
+	assert.Equal(t, "ABC Bank", account.Attributes.ProcessingService, "ProcessingService incorrect")
+
+	encoded, err := json.Marshal(account)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.JSONEq(t, data, string(encoded), "Account should round trip")
+}
+
+func TestAccount_roundTrip_business(t *testing.T) {
+	data := `{"id":"a1b2c3","organisation_id":"b","type":"accounts","attributes":{` +
+		`"acceptance_qualifier":"same_day","account_classification":"Business","country":"GB","name":["Form3"],` +
+		`"organisation_identification":{"identification":"123654","address":["10 Avenue des Champs"],"city":"London","country":"GB",` +
+		`"actors":[{"name":["Jeff Page"],"birth_date":"1970-01-01","residency":"GB"}]}},"version":3}`
+
+	account := new(Account)
+	err := json.Unmarshal([]byte(data), account)
+
+	assert.Nil(t, err, "Error should be nil")
+	identification := account.Attributes.OrganisationIdentification
+	assert.Equal(t, "123654", identification.Identification, "OrganisationIdentification.Identification incorrect")
+	assert.Equal(t, []OrganisationActor{{Name: []string{"Jeff Page"}, BirthDate: "1970-01-01", Residency: "GB"}}, identification.Actors, "OrganisationIdentification.Actors incorrect")
+	assert.Equal(t, "same_day", account.Attributes.AcceptanceQualifier, "AcceptanceQualifier incorrect")
+
+	encoded, err := json.Marshal(account)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.JSONEq(t, data, string(encoded), "Account should round trip")
+}
@@ -0,0 +1,141 @@
+package form3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	defaultWaitInterval    = 500 * time.Millisecond
+	defaultWaitMaxInterval = 10 * time.Second
+	defaultWaitMultiplier  = 2
+)
+
+// WaitOptions configures AccountsService.WaitForStatus. A nil WaitOptions uses the defaults.
+type WaitOptions struct {
+	// Interval is the wait before the second Get, 500ms by default. It grows by Multiplier
+	// after each Get, 2 by default, up to MaxInterval, 10s by default
+	Interval    time.Duration
+	MaxInterval time.Duration
+	Multiplier  float64
+	// Timeout limits the wait, it is only limited by ctx by default
+	Timeout time.Duration
+	// TerminalStatuses stop the wait with a TerminalStatusError when reached, AcctStatusClosed by default
+	TerminalStatuses []AccountStatus
+	// OnProgress is called with every account retrieved while waiting
+	OnProgress func(attempt int, account *Account)
+	// CallOptions are passed to every Get
+	CallOptions []CallOption
+}
+
+// WaitTimeoutError is returned when the account does not reach a target status in time
+type WaitTimeoutError struct {
+	// LastAccount is the last account retrieved, nil if none was
+	LastAccount *Account
+	Err         error
+}
+
+func (e *WaitTimeoutError) Error() string {
+	if e.LastAccount == nil || e.LastAccount.Attributes == nil {
+		return fmt.Sprintf("timed out waiting for account status: %v", e.Err)
+	}
+
+	return fmt.Sprintf("timed out waiting for account status, last status %q: %v", e.LastAccount.Attributes.Status, e.Err)
+}
+
+func (e *WaitTimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// TerminalStatusError is returned when the account reaches a terminal status that is not a target
+type TerminalStatusError struct {
+	Account *Account
+}
+
+func (e *TerminalStatusError) Error() string {
+	return fmt.Sprintf("account %s reached terminal status %q", e.Account.ID, accountStatus(e.Account))
+}
+
+// WaitForStatus polls an account by its id until its status is one of targetStatuses and returns it.
+// The polling backs off between calls, and stops early when the account reaches a terminal status.
+func (s *AccountsService) WaitForStatus(ctx context.Context, id string, opts *WaitOptions, targetStatuses ...AccountStatus) (*Account, error) {
+	if opts == nil {
+		opts = &WaitOptions{}
+	}
+	if len(targetStatuses) == 0 {
+		return nil, errors.New("at least one target status is required")
+	}
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	terminalStatuses := opts.TerminalStatuses
+	if terminalStatuses == nil {
+		terminalStatuses = []AccountStatus{AcctStatusClosed}
+	}
+	interval := durationOrDefault(opts.Interval, defaultWaitInterval)
+	maxInterval := durationOrDefault(opts.MaxInterval, defaultWaitMaxInterval)
+	multiplier := opts.Multiplier
+	if multiplier < 1 {
+		multiplier = defaultWaitMultiplier
+	}
+
+	var last *Account
+	for attempt := 1; ; attempt++ {
+		account, _, err := s.Get(ctx, id, opts.CallOptions...)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, &WaitTimeoutError{LastAccount: last, Err: ctx.Err()}
+			}
+			return nil, err
+		}
+		last = account
+
+		if opts.OnProgress != nil {
+			opts.OnProgress(attempt, account)
+		}
+
+		status := accountStatus(account)
+		if containsStatus(targetStatuses, status) {
+			return account, nil
+		}
+		if containsStatus(terminalStatuses, status) {
+			return nil, &TerminalStatusError{Account: account}
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, &WaitTimeoutError{LastAccount: last, Err: ctx.Err()}
+		case <-timer.C:
+		}
+
+		interval = time.Duration(float64(interval) * multiplier)
+		if interval > maxInterval {
+			interval = maxInterval
+		}
+	}
+}
+
+func accountStatus(account *Account) AccountStatus {
+	if account.Attributes == nil {
+		return ""
+	}
+
+	return account.Attributes.Status
+}
+
+func containsStatus(statuses []AccountStatus, status AccountStatus) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+
+	return false
+}
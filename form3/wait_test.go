@@ -0,0 +1,94 @@
+package form3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// mockedStatusesHttpClient answers each Get with the next status, repeating the last one
+func mockedStatusesHttpClient(statuses ...AccountStatus) (mockedHttpClientHandler, *int) {
+	calls := 0
+	return mockedHttpClientHandler(func(req *http.Request) (*http.Response, error) {
+		status := statuses[len(statuses)-1]
+		if calls < len(statuses) {
+			status = statuses[calls]
+		}
+		calls++
+		body := fmt.Sprintf(`{"data":{"id":"a1b2c3","type":"accounts","version":%d,"attributes":{"status":"%s"}}}`, calls, status)
+		return mockedResponse(http.StatusOK, body, nil), nil
+	}), &calls
+}
+
+func TestAccountsService_WaitForStatus(t *testing.T) {
+	httpClient, calls := mockedStatusesHttpClient(AcctStatusPending, AcctStatusPending, AcctStatusConfirmed)
+	client, _ := NewRestClient(httpClient, NewRestClientParams{BaseUrl: baseFakeUrl})
+	service := NewAccountsService(client)
+
+	var progress []AccountStatus
+	opts := &WaitOptions{
+		Interval: time.Millisecond,
+		OnProgress: func(attempt int, account *Account) {
+			progress = append(progress, account.Attributes.Status)
+		},
+	}
+	account, err := service.WaitForStatus(context.Background(), "a1b2c3", opts, AcctStatusConfirmed)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, AcctStatusConfirmed, account.Attributes.Status, "account.Attributes.Status incorrect")
+	assert.Equal(t, 3, *calls, "Get calls incorrect")
+	assert.Equal(t, []AccountStatus{AcctStatusPending, AcctStatusPending, AcctStatusConfirmed}, progress, "Progress incorrect")
+}
+
+func TestAccountsService_WaitForStatus_terminalStatus(t *testing.T) {
+	httpClient, calls := mockedStatusesHttpClient(AcctStatusPending, AcctStatusClosed)
+	client, _ := NewRestClient(httpClient, NewRestClientParams{BaseUrl: baseFakeUrl})
+	service := NewAccountsService(client)
+
+	account, err := service.WaitForStatus(context.Background(), "a1b2c3", &WaitOptions{Interval: time.Millisecond}, AcctStatusConfirmed)
+
+	assert.Nil(t, account, "Account should be nil")
+	var terminalErr *TerminalStatusError
+	assert.True(t, errors.As(err, &terminalErr), "Error should be a TerminalStatusError")
+	assert.Equal(t, AcctStatusClosed, terminalErr.Account.Attributes.Status, "Terminal status incorrect")
+	assert.Equal(t, 2, *calls, "Get calls incorrect")
+}
+
+func TestAccountsService_WaitForStatus_timeout(t *testing.T) {
+	httpClient, _ := mockedStatusesHttpClient(AcctStatusPending)
+	client, _ := NewRestClient(httpClient, NewRestClientParams{BaseUrl: baseFakeUrl})
+	service := NewAccountsService(client)
+
+	opts := &WaitOptions{Interval: time.Millisecond, MaxInterval: 5 * time.Millisecond, Timeout: 30 * time.Millisecond}
+	account, err := service.WaitForStatus(context.Background(), "a1b2c3", opts, AcctStatusConfirmed)
+
+	assert.Nil(t, account, "Account should be nil")
+	var timeoutErr *WaitTimeoutError
+	assert.True(t, errors.As(err, &timeoutErr), "Error should be a WaitTimeoutError")
+	assert.Equal(t, AcctStatusPending, timeoutErr.LastAccount.Attributes.Status, "Last observed status incorrect")
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "Error should wrap the context error")
+	assert.Contains(t, err.Error(), `last status "pending"`, "Error message expected")
+}
+
+func TestAccountsService_WaitForStatus_getError(t *testing.T) {
+	mockedHttpClient := mockedHttpClientHandler(func(req *http.Request) (*http.Response, error) {
+		return mockedResponse(http.StatusNotFound, `{"error_message":"record a1b2c3 does not exist"}`, nil), nil
+	})
+	client, _ := NewRestClient(mockedHttpClient, NewRestClientParams{BaseUrl: baseFakeUrl})
+
+	_, err := NewAccountsService(client).WaitForStatus(context.Background(), "a1b2c3", nil, AcctStatusConfirmed)
+
+	assert.Equal(t, "record a1b2c3 does not exist", err.Error(), "Error message expected")
+}
+
+func TestAccountsService_WaitForStatus_withoutTargets(t *testing.T) {
+	client, _ := NewRestClient(nil, NewRestClientParams{BaseUrl: baseFakeUrl})
+
+	_, err := NewAccountsService(client).WaitForStatus(context.Background(), "a1b2c3", nil)
+
+	assert.NotNil(t, err, "Error should be not nil")
+}
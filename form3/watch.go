@@ -0,0 +1,400 @@
+package form3
+
+import (
+	"context"
+	"encoding/json"
+	"form3-interview-accountapi/form3/internal/atomicfile"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+const defaultWatchInterval = 5 * time.Second
+
+type AccountEventType string
+
+const (
+	AccountEventCreated AccountEventType = "created"
+	AccountEventUpdated AccountEventType = "updated"
+	AccountEventDeleted AccountEventType = "deleted"
+	// AccountEventError carries a failed poll in Err, the watch goes on with the next poll
+	AccountEventError AccountEventType = "error"
+)
+
+// AccountEvent is a change of an account seen by AccountsService.Watch.
+// Deleted events only hold the id and the last version seen of the account.
+type AccountEvent struct {
+	Type    AccountEventType
+	Account *Account
+	Err     error
+}
+
+// WatchCheckpoint is the state a watch resumes from
+type WatchCheckpoint struct {
+	// Cursor is the latest modified_on of the accounts processed, each poll lists the accounts modified since
+	Cursor *time.Time `json:"cursor,omitempty"`
+	// Versions is the last version seen of each account, to tell created accounts apart and report the deleted ones
+	Versions map[string]int `json:"versions"`
+}
+
+// CheckpointStore persists the checkpoint of a watch so it can resume after a restart
+type CheckpointStore interface {
+	// Load returns the saved checkpoint, nil when there is none
+	Load(ctx context.Context) (*WatchCheckpoint, error)
+	Save(ctx context.Context, checkpoint *WatchCheckpoint) error
+}
+
+// WatchFilter configures AccountsService.Watch
+type WatchFilter struct {
+	// Filter restricts the watched accounts, as in ListOptions
+	Filter map[string]string
+	// PageSize is the size of the pages listed on each poll, 100 by default
+	PageSize int
+	// Interval is the wait between polls, 5s by default
+	Interval time.Duration
+	// Store persists the checkpoint after each poll, the watch starts from scratch when nil
+	Store CheckpointStore
+	// CallOptions are passed to every List
+	CallOptions []CallOption
+}
+
+// WatchModifiedFromFilter is the filter of the accounts modified at or after a time, set by Watch to its cursor
+// in RFC 3339 format along with sorting by WatchSort. A page listing an account modified before the cursor or out
+// of order shows the API does not support them: the pages are then listed by page number, so every poll lists
+// every account, and the accounts known at their version are ignored.
+const (
+	WatchModifiedFromFilter = "modified_on_from"
+	WatchSort               = "modified_on"
+)
+
+// Watch polls the accounts matching the filter and emits an event for each account created, updated or
+// deleted since the previous poll, ordered by modified_on. The first poll of a watch without a checkpoint
+// emits every existing account as created. The channel is closed when ctx is done.
+//
+// Each poll lists the accounts modified since the checkpoint cursor. Deletes are detected by counting the
+// accounts, and only when the API counts fewer accounts than known are they all listed to find the missing ones.
+func (s *AccountsService) Watch(ctx context.Context, filter *WatchFilter) (<-chan AccountEvent, error) {
+	if filter == nil {
+		filter = &WatchFilter{}
+	}
+
+	checkpoint := &WatchCheckpoint{}
+	if filter.Store != nil {
+		saved, err := filter.Store.Load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if saved != nil {
+			checkpoint = saved
+		}
+	}
+	if checkpoint.Versions == nil {
+		checkpoint.Versions = map[string]int{}
+	}
+
+	events := make(chan AccountEvent)
+	go func() {
+		defer close(events)
+
+		interval := durationOrDefault(filter.Interval, defaultWatchInterval)
+		for {
+			if err := s.poll(ctx, filter, checkpoint, events); err != nil && ctx.Err() == nil {
+				if !sendEvent(ctx, events, AccountEvent{Type: AccountEventError, Err: err}) {
+					return
+				}
+			}
+
+			timer := time.NewTimer(interval)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// poll lists the accounts modified since the checkpoint and the deleted ones, and emits their events
+func (s *AccountsService) poll(ctx context.Context, filter *WatchFilter, checkpoint *WatchCheckpoint, events chan<- AccountEvent) error {
+	accounts, err := s.listModified(ctx, filter, checkpoint.Cursor)
+	if err != nil {
+		return err
+	}
+
+	for _, account := range accounts {
+		version, known := checkpoint.Versions[account.ID]
+		if known && version == account.Version {
+			continue
+		}
+
+		eventType := AccountEventUpdated
+		if !known {
+			eventType = AccountEventCreated
+		}
+		if !sendEvent(ctx, events, AccountEvent{Type: eventType, Account: account}) {
+			return ctx.Err()
+		}
+
+		checkpoint.Versions[account.ID] = account.Version
+		if account.ModifiedOn != nil && (checkpoint.Cursor == nil || account.ModifiedOn.After(*checkpoint.Cursor)) {
+			checkpoint.Cursor = account.ModifiedOn
+		}
+	}
+
+	deleted, err := s.listDeleted(ctx, filter, checkpoint.Versions)
+	if err != nil {
+		return err
+	}
+	for _, id := range deleted {
+		account := &Account{ID: id, Type: AcctTypeAccounts, Version: checkpoint.Versions[id]}
+		if !sendEvent(ctx, events, AccountEvent{Type: AccountEventDeleted, Account: account}) {
+			return ctx.Err()
+		}
+
+		delete(checkpoint.Versions, id)
+	}
+
+	if filter.Store != nil {
+		return filter.Store.Save(ctx, checkpoint)
+	}
+
+	return nil
+}
+
+// listModified lists the accounts modified since cursor, every account without cursor, sorted by modified_on.
+// The pages are listed from the last modified_on of the previous page rather than by page number, so an account
+// updated during the listing moves after the ones left instead of shifting them to a page already listed.
+// Once a page does not follow the cursor, the next pages are listed by page number.
+func (s *AccountsService) listModified(ctx context.Context, filter *WatchFilter, cursor *time.Time) ([]*Account, error) {
+	page := ListOptions{PageSize: filter.PageSize, Sort: WatchSort}
+	if page.PageSize <= 0 {
+		page.PageSize = defaultListEachPageSize
+	}
+	var from time.Time
+	if cursor != nil {
+		from = *cursor
+	}
+
+	var accounts []*Account
+	positions := map[string]int{}
+	keyset := true
+	for {
+		page.Filter = make(map[string]string, len(filter.Filter)+1)
+		for key, value := range filter.Filter {
+			page.Filter[key] = value
+		}
+		if !from.IsZero() {
+			page.Filter[WatchModifiedFromFilter] = from.Format(time.RFC3339Nano)
+		}
+
+		listed, resp, err := s.List(ctx, &page, filter.CallOptions...)
+		if err != nil {
+			return nil, err
+		}
+
+		// the accounts modified at the cursor are listed again by the next page
+		last := from
+		for i := range listed {
+			account := &listed[i]
+			if position, ok := positions[account.ID]; ok {
+				if account.Version > accounts[position].Version {
+					accounts[position] = account
+				}
+			} else {
+				positions[account.ID] = len(accounts)
+				accounts = append(accounts, account)
+			}
+			if modifiedOn(account).After(last) {
+				last = modifiedOn(account)
+			}
+		}
+
+		if !hasNextPage(resp, len(listed), page.PageSize) {
+			break
+		}
+		keyset = keyset && followsCursor(listed, from)
+		// a page of accounts all modified at the cursor is left by page number
+		if keyset && last.After(from) {
+			from = last
+			page.PageNumber = 0
+		} else {
+			page.PageNumber++
+		}
+	}
+
+	sort.SliceStable(accounts, func(i, j int) bool {
+		return modifiedOn(accounts[i]).Before(modifiedOn(accounts[j]))
+	})
+
+	return accounts, nil
+}
+
+// followsCursor reports whether the accounts are sorted by modified_on and none was modified before from
+func followsCursor(accounts []Account, from time.Time) bool {
+	previous := from
+	for i := range accounts {
+		modified := modifiedOn(&accounts[i])
+		if modified.Before(previous) {
+			return false
+		}
+		previous = modified
+	}
+
+	return true
+}
+
+// listDeleted returns the ids of the known accounts deleted, sorted. The accounts are only all listed when
+// the API counts fewer than known, or does not count them. An account missing from the listing is only
+// reported when as many are missing as the count tells, or when a Get does not find it: deleting an
+// account while listing by page number shifts the next ones, and one of them can be skipped.
+func (s *AccountsService) listDeleted(ctx context.Context, filter *WatchFilter, known map[string]int) ([]string, error) {
+	count, counted, err := s.countAccounts(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if counted && count >= len(known) {
+		return nil, nil
+	}
+
+	listed := make(map[string]bool, len(known))
+	err = s.ListEach(ctx, &ListOptions{Filter: filter.Filter, PageSize: filter.PageSize}, func(account *Account) error {
+		listed[account.ID] = true
+		return nil
+	}, filter.CallOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	for id := range known {
+		if !listed[id] {
+			missing = append(missing, id)
+		}
+	}
+	sort.Strings(missing)
+	if counted && len(missing) == len(known)-count {
+		return missing, nil
+	}
+
+	var deleted []string
+	for _, id := range missing {
+		_, resp, err := s.Get(ctx, id, filter.CallOptions...)
+		if isStatus(resp, http.StatusNotFound) {
+			deleted = append(deleted, id)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return deleted, nil
+}
+
+// countAccounts returns the number of accounts matching the filter from the metadata or the links of a
+// list of one account per page, false when the API sends neither
+func (s *AccountsService) countAccounts(ctx context.Context, filter *WatchFilter) (int, bool, error) {
+	accounts, resp, err := s.List(ctx, &ListOptions{Filter: filter.Filter, PageSize: 1}, filter.CallOptions...)
+	if err != nil {
+		return 0, false, err
+	}
+
+	if resp.Meta != nil && resp.Meta.Count != nil {
+		return *resp.Meta.Count, true, nil
+	}
+	if resp.Links != nil && len(accounts) <= 1 {
+		if last, ok := resp.Links.LastPage(); ok {
+			return (last + 1) * len(accounts), true, nil
+		}
+	}
+
+	return 0, false, nil
+}
+
+func sendEvent(ctx context.Context, events chan<- AccountEvent, event AccountEvent) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case events <- event:
+		return true
+	}
+}
+
+func modifiedOn(account *Account) time.Time {
+	if account.ModifiedOn == nil {
+		return time.Time{}
+	}
+
+	return *account.ModifiedOn
+}
+
+// MemoryCheckpointStore keeps the checkpoint in memory
+type MemoryCheckpointStore struct {
+	mu         sync.Mutex
+	checkpoint *WatchCheckpoint
+}
+
+func (s *MemoryCheckpointStore) Load(ctx context.Context) (*WatchCheckpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return copyCheckpoint(s.checkpoint), nil
+}
+
+func (s *MemoryCheckpointStore) Save(ctx context.Context, checkpoint *WatchCheckpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.checkpoint = copyCheckpoint(checkpoint)
+	return nil
+}
+
+func copyCheckpoint(checkpoint *WatchCheckpoint) *WatchCheckpoint {
+	if checkpoint == nil {
+		return nil
+	}
+
+	versions := make(map[string]int, len(checkpoint.Versions))
+	for id, version := range checkpoint.Versions {
+		versions[id] = version
+	}
+
+	return &WatchCheckpoint{Cursor: checkpoint.Cursor, Versions: versions}
+}
+
+// FileCheckpointStore keeps the checkpoint in a JSON file
+type FileCheckpointStore struct {
+	Path string
+}
+
+func (s *FileCheckpointStore) Load(ctx context.Context) (*WatchCheckpoint, error) {
+	data, err := os.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	checkpoint := &WatchCheckpoint{}
+	if err = json.Unmarshal(data, checkpoint); err != nil {
+		return nil, err
+	}
+
+	return checkpoint, nil
+}
+
+// Save writes the checkpoint, replacing the file at once so a crash never leaves it half written
+func (s *FileCheckpointStore) Save(ctx context.Context, checkpoint *WatchCheckpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+
+	return atomicfile.WriteFile(s.Path, data)
+}
@@ -0,0 +1,355 @@
+package form3
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// watchedAPI is a fake account endpoint whose accounts can be changed while a watch runs.
+// Lists are sorted by modified_on and support the modified_on_from filter, paging and the count metadata.
+type watchedAPI struct {
+	mu       sync.Mutex
+	accounts map[string]Account
+	clock    time.Time
+	// skipped accounts are left out of the lists not filtered by modified_on, as if the pages shifted
+	skipped map[string]bool
+	// lists are the queries of the lists received
+	lists []string
+	// ignoresCursor makes the lists ignore the modified_on_from filter and sort by id, like the Form3 API
+	ignoresCursor bool
+	// countless sends the metadata without count
+	countless bool
+}
+
+func newWatchedAPI() *watchedAPI {
+	return &watchedAPI{
+		accounts: map[string]Account{},
+		clock:    time.Date(2023, 3, 1, 10, 0, 0, 0, time.UTC),
+		skipped:  map[string]bool{},
+	}
+}
+
+// change applies the changes at once, so a poll sees all or none of them
+func (api *watchedAPI) change(changes func()) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	changes()
+}
+
+func (api *watchedAPI) put(id string, version int) {
+	api.clock = api.clock.Add(time.Second)
+	modifiedOn := api.clock
+	api.accounts[id] = Account{ID: id, Type: AcctTypeAccounts, Version: version, ModifiedOn: &modifiedOn}
+}
+
+func (api *watchedAPI) delete(id string) {
+	delete(api.accounts, id)
+}
+
+func (api *watchedAPI) Do(req *http.Request) (*http.Response, error) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	if id := strings.TrimPrefix(req.URL.Path, "/v1/organisation/accounts/"); id != req.URL.Path {
+		account, ok := api.accounts[id]
+		if !ok {
+			return mockedResponse(http.StatusNotFound, `{"error_message":"record `+id+` does not exist"}`, nil), nil
+		}
+		data, _ := json.Marshal(map[string]any{"data": account})
+		return mockedResponse(http.StatusOK, string(data), nil), nil
+	}
+
+	query := req.URL.Query()
+	api.lists = append(api.lists, query.Encode())
+	var from time.Time
+	if value := query.Get("filter[modified_on_from]"); value != "" && !api.ignoresCursor {
+		from, _ = time.Parse(time.RFC3339Nano, value)
+	}
+	accounts := []Account{}
+	for _, account := range api.accounts {
+		if account.ModifiedOn.Before(from) || (from.IsZero() && query.Get("page[size]") != "1" && api.skipped[account.ID]) {
+			continue
+		}
+		accounts = append(accounts, account)
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		if api.ignoresCursor {
+			return accounts[i].ID < accounts[j].ID
+		}
+		return accounts[i].ModifiedOn.Before(*accounts[j].ModifiedOn)
+	})
+
+	count := len(accounts)
+	number, _ := strconv.Atoi(query.Get("page[number]"))
+	size, _ := strconv.Atoi(query.Get("page[size]"))
+	start, end := number*size, (number+1)*size
+	if end > len(accounts) {
+		end = len(accounts)
+	}
+	if start > end {
+		start = end
+	}
+	accounts = accounts[start:end]
+	meta := map[string]any{"count": count}
+	if api.countless {
+		meta = map[string]any{"server": "fake"}
+	}
+	data, _ := json.Marshal(map[string]any{"data": accounts, "meta": meta})
+
+	return mockedResponse(http.StatusOK, string(data), nil), nil
+}
+
+// listsSince returns the lists received since the given number of lists, with their queries unescaped
+func (api *watchedAPI) listsSince(n int) []string {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	var lists []string
+	for _, query := range api.lists[n:] {
+		unescaped, _ := url.QueryUnescape(query)
+		lists = append(lists, unescaped)
+	}
+
+	return lists
+}
+
+func (api *watchedAPI) listCount() int {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	return len(api.lists)
+}
+
+type watchedEvent struct {
+	eventType AccountEventType
+	id        string
+	version   int
+}
+
+func receiveEvents(t *testing.T, events <-chan AccountEvent, count int) []watchedEvent {
+	t.Helper()
+
+	var received []watchedEvent
+	for len(received) < count {
+		select {
+		case event := <-events:
+			assert.Nil(t, event.Err, "Event error should be nil")
+			received = append(received, watchedEvent{event.Type, event.Account.ID, event.Account.Version})
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting for events, received %v", received)
+		}
+	}
+
+	return received
+}
+
+func TestAccountsService_Watch(t *testing.T) {
+	api := newWatchedAPI()
+	api.put("a2", 0)
+	api.put("a1", 0)
+	client, _ := NewRestClient(api, NewRestClientParams{BaseUrl: baseFakeUrl})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := NewAccountsService(client).Watch(ctx, &WatchFilter{Interval: time.Millisecond})
+	assert.Nil(t, err, "Error should be nil")
+
+	assert.Equal(t, []watchedEvent{
+		{AccountEventCreated, "a2", 0},
+		{AccountEventCreated, "a1", 0},
+	}, receiveEvents(t, events, 2), "Initial events incorrect")
+
+	api.change(func() {
+		api.delete("a2")
+		api.put("a1", 1)
+		api.put("a3", 0)
+	})
+
+	assert.Equal(t, []watchedEvent{
+		{AccountEventUpdated, "a1", 1},
+		{AccountEventCreated, "a3", 0},
+		{AccountEventDeleted, "a2", 0},
+	}, receiveEvents(t, events, 3), "Change events incorrect")
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestAccountsService_Watch_resumesFromCheckpoint(t *testing.T) {
+	api := newWatchedAPI()
+	api.put("a1", 0)
+	api.put("a2", 0)
+	client, _ := NewRestClient(api, NewRestClientParams{BaseUrl: baseFakeUrl})
+	service := NewAccountsService(client)
+	store := &FileCheckpointStore{Path: filepath.Join(t.TempDir(), "checkpoint.json")}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, _ := service.Watch(ctx, &WatchFilter{Interval: time.Hour, Store: store})
+	receiveEvents(t, events, 2)
+	cancel()
+	for range events {
+	}
+
+	checkpoint, err := store.Load(context.Background())
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, map[string]int{"a1": 0, "a2": 0}, checkpoint.Versions, "Saved versions incorrect")
+	assert.Equal(t, time.Date(2023, 3, 1, 10, 0, 2, 0, time.UTC), checkpoint.Cursor.UTC(), "Saved cursor incorrect")
+
+	// changes while the watch is stopped
+	api.change(func() {
+		api.put("a2", 1)
+		api.delete("a1")
+	})
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	events, _ = service.Watch(ctx, &WatchFilter{Interval: time.Hour, Store: store})
+
+	assert.Equal(t, []watchedEvent{
+		{AccountEventUpdated, "a2", 1},
+		{AccountEventDeleted, "a1", 0},
+	}, receiveEvents(t, events, 2), "Resumed events incorrect")
+}
+
+func pollEvents(t *testing.T, service *AccountsService, checkpoint *WatchCheckpoint) []watchedEvent {
+	t.Helper()
+
+	events := make(chan AccountEvent, 10)
+	err := service.poll(context.Background(), &WatchFilter{}, checkpoint, events)
+	assert.Nil(t, err, "Poll error should be nil")
+	close(events)
+
+	var received []watchedEvent
+	for event := range events {
+		received = append(received, watchedEvent{event.Type, event.Account.ID, event.Account.Version})
+	}
+
+	return received
+}
+
+func TestAccountsService_Watch_listsFromCursor(t *testing.T) {
+	api := newWatchedAPI()
+	api.put("a1", 0)
+	api.put("a2", 0)
+	client, _ := NewRestClient(api, NewRestClientParams{BaseUrl: baseFakeUrl})
+	service := NewAccountsService(client)
+	checkpoint := &WatchCheckpoint{Versions: map[string]int{}}
+	pollEvents(t, service, checkpoint)
+
+	api.change(func() {
+		api.put("a1", 1)
+	})
+	lists := api.listCount()
+
+	assert.Equal(t, []watchedEvent{{AccountEventUpdated, "a1", 1}}, pollEvents(t, service, checkpoint), "Events incorrect")
+	assert.Equal(t, []string{
+		"filter[modified_on_from]=2023-03-01T10:00:02Z&page[size]=100&sort=modified_on",
+		"page[size]=1",
+	}, api.listsSince(lists), "A poll without deletes should only list the modified accounts and count them")
+}
+
+func TestAccountsService_Watch_apiIgnoringCursor(t *testing.T) {
+	api := newWatchedAPI()
+	api.ignoresCursor = true
+	for _, id := range []string{"a5", "a4", "a3", "a2", "a1"} {
+		api.put(id, 0)
+	}
+	client, _ := NewRestClient(api, NewRestClientParams{BaseUrl: baseFakeUrl})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, _ := NewAccountsService(client).Watch(ctx, &WatchFilter{PageSize: 2, Interval: time.Hour})
+
+	assert.Equal(t, []watchedEvent{
+		{AccountEventCreated, "a5", 0},
+		{AccountEventCreated, "a4", 0},
+		{AccountEventCreated, "a3", 0},
+		{AccountEventCreated, "a2", 0},
+		{AccountEventCreated, "a1", 0},
+	}, receiveEvents(t, events, 5), "events incorrect")
+	assert.Eventually(t, func() bool { return api.listCount() == 4 }, time.Second, time.Millisecond, "lists incorrect")
+	assert.Equal(t, []string{
+		"page[size]=2&sort=modified_on",
+		"page[number]=1&page[size]=2&sort=modified_on",
+		"page[number]=2&page[size]=2&sort=modified_on",
+		"page[size]=1",
+	}, api.listsSince(0), "pages should be listed by number once the cursor is ignored")
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestAccountsService_countAccounts_withoutCount(t *testing.T) {
+	api := newWatchedAPI()
+	api.countless = true
+	api.put("a1", 0)
+	client, _ := NewRestClient(api, NewRestClientParams{BaseUrl: baseFakeUrl})
+
+	_, counted, err := NewAccountsService(client).countAccounts(context.Background(), &WatchFilter{})
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.False(t, counted, "metadata without count should not count the accounts")
+}
+
+func TestAccountsService_Watch_skippedAccountNotDeleted(t *testing.T) {
+	api := newWatchedAPI()
+	api.put("a1", 0)
+	api.put("a2", 0)
+	api.put("a3", 0)
+	client, _ := NewRestClient(api, NewRestClientParams{BaseUrl: baseFakeUrl})
+	service := NewAccountsService(client)
+	checkpoint := &WatchCheckpoint{Versions: map[string]int{}}
+	pollEvents(t, service, checkpoint)
+
+	api.change(func() {
+		api.delete("a1")
+		api.skipped["a3"] = true
+	})
+
+	assert.Equal(t, []watchedEvent{{AccountEventDeleted, "a1", 0}}, pollEvents(t, service, checkpoint), "Events incorrect")
+	assert.Equal(t, map[string]int{"a2": 0, "a3": 0}, checkpoint.Versions, "Versions incorrect")
+}
+
+func TestAccountsService_Watch_pollError(t *testing.T) {
+	mockedHttpClient := mockedHttpClientHandler(func(req *http.Request) (*http.Response, error) {
+		return mockedResponse(http.StatusInternalServerError, `{"error_message":"boom"}`, nil), nil
+	})
+	client, _ := NewRestClient(mockedHttpClient, NewRestClientParams{BaseUrl: baseFakeUrl})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, _ := NewAccountsService(client).Watch(ctx, &WatchFilter{Interval: time.Hour})
+	event := <-events
+
+	assert.Equal(t, AccountEventError, event.Type, "Event type incorrect")
+	assert.Equal(t, "boom", event.Err.Error(), "Event error incorrect")
+}
+
+func TestMemoryCheckpointStore(t *testing.T) {
+	store := &MemoryCheckpointStore{}
+	ctx := context.Background()
+
+	checkpoint, err := store.Load(ctx)
+	assert.Nil(t, err, "Error should be nil")
+	assert.Nil(t, checkpoint, "Checkpoint should be nil")
+
+	saved := &WatchCheckpoint{Versions: map[string]int{"a1": 1}}
+	_ = store.Save(ctx, saved)
+	saved.Versions["a1"] = 2
+
+	checkpoint, _ = store.Load(ctx)
+	assert.Equal(t, map[string]int{"a1": 1}, checkpoint.Versions, "Store should keep a copy")
+}